@@ -0,0 +1,60 @@
+package keywordmap
+
+import "golang.org/x/exp/constraints"
+
+// batchWidth is the number of trie walks that KeywordIndices advances in
+// lockstep.
+const batchWidth = 4
+
+// KeywordIndices sets indices[i] to KeywordIndex(trie, words[i]) for each i.
+// It panics if indices is shorter than words. Rather than performing each
+// lookup in turn, it advances several lookups in lockstep. Each step of a trie
+// walk depends on a load from the backing array, but the steps of independent
+// walks do not depend on each other, so their loads can overlap. This pays off
+// for tries too large to fit in the L1/L2 cache. For small tries, a loop calling
+// KeywordIndex may be faster, as it can give up on each non-keyword as soon as
+// its lookup fails.
+func KeywordIndices[T ByteIndexable, I constraints.Unsigned](trie GenericTrie[I], words []T, indices []int) {
+	ba := trie.backingSlice
+	indices = indices[:len(words)]
+
+	i := 0
+	for ; i+batchWidth <= len(words); i += batchWidth {
+		w0, w1, w2, w3 := words[i], words[i+1], words[i+2], words[i+3]
+		o0, o1, o2, o3 := 1, 1, 1, 1
+
+		n := max(len(w0), len(w1), len(w2), len(w3))
+		for j := 0; j < n; j++ {
+			// A walk that fails ends up looping round the nowhere node, so (as in
+			// KeywordIndex) there is no need to branch on failure for each walk.
+			if j < len(w0) {
+				b := int(w0[j])
+				o0 = int(ba[int(ba[o0*nodeSize+(b>>4)])*nodeSize+(b&0xF)])
+			}
+			if j < len(w1) {
+				b := int(w1[j])
+				o1 = int(ba[int(ba[o1*nodeSize+(b>>4)])*nodeSize+(b&0xF)])
+			}
+			if j < len(w2) {
+				b := int(w2[j])
+				o2 = int(ba[int(ba[o2*nodeSize+(b>>4)])*nodeSize+(b&0xF)])
+			}
+			if j < len(w3) {
+				b := int(w3[j])
+				o3 = int(ba[int(ba[o3*nodeSize+(b>>4)])*nodeSize+(b&0xF)])
+			}
+			if o0|o1|o2|o3 == 0 {
+				break
+			}
+		}
+
+		indices[i] = int(ba[o0*nodeSize+nodeSize-1]) - 1
+		indices[i+1] = int(ba[o1*nodeSize+nodeSize-1]) - 1
+		indices[i+2] = int(ba[o2*nodeSize+nodeSize-1]) - 1
+		indices[i+3] = int(ba[o3*nodeSize+nodeSize-1]) - 1
+	}
+
+	for ; i < len(words); i++ {
+		indices[i] = KeywordIndex(trie, words[i])
+	}
+}
@@ -0,0 +1,73 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestKeywordIndices(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+
+	words := append([]string{"", "a", "zzzzzzzzzzzzzzzzzzz"}, td.ToTest...)
+	for n := 0; n <= len(words); n++ {
+		indices := make([]int, n)
+		KeywordIndices(trie, words[:n], indices)
+		for i, w := range words[:n] {
+			if indices[i] != KeywordIndex(trie, w) {
+				t.Fatalf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, indices[i])
+			}
+		}
+	}
+}
+
+func TestKeywordIndicesShortOutput(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expecting KeywordIndices to panic")
+		}
+	}()
+	KeywordIndices(MakeEmptyTrie[uint16](), []string{"a", "b"}, make([]int, 1))
+}
+
+// The benchmarks use a trie that is too large to fit in L1/L2 cache, as
+// overlapping loads can't help if they all hit the L1 cache anyway.
+func getBatchBenchmarkData() (GenericTrie[uint32], []string) {
+	keywords := getIdentifiers(20000)
+	trie, ok := MakeGenericTrie[uint32](keywords)
+	if !ok {
+		panic("Internal error [34] in benchmark")
+	}
+	r := rand.New(rand.NewSource(randSeed))
+	words := getIdentifiers(1000)
+	for i := range words {
+		if r.Intn(2) == 0 {
+			words[i] = keywords[r.Intn(len(keywords))]
+		}
+	}
+	return trie, words
+}
+
+func BenchmarkTrieLoop(b *testing.B) {
+	trie, words := getBatchBenchmarkData()
+	indices := make([]int, len(words))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range words {
+			indices[j] = KeywordIndex(trie, w)
+		}
+	}
+}
+
+func BenchmarkTrieBatch(b *testing.B) {
+	trie, words := getBatchBenchmarkData()
+	indices := make([]int, len(words))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		KeywordIndices(trie, words, indices)
+	}
+}
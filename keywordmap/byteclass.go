@@ -0,0 +1,149 @@
+package keywordmap
+
+import "golang.org/x/exp/constraints"
+
+// ByteClassTable maps each byte to a class. Class 0 means that the byte is not
+// in the alphabet. Bytes that share a class are treated as identical by a
+// GenericByteClassTrie.
+type ByteClassTable [256]uint8
+
+// ByteClassTrie is the recommended instantiation of GenericByteClassTrie.
+type ByteClassTrie = GenericByteClassTrie[uint16]
+
+// GenericByteClassTrie is a trie over a reduced alphabet. Rather than splitting
+// each byte into two nibbles, it maps each byte to a class using a
+// ByteClassTable, and then takes a single step per byte. Each node has one
+// child slot for each class, so when the keywords draw on a small set of
+// bytes, both the nodes and the number of steps per lookup are smaller than for
+// GenericTrie. Inputs containing a byte outside the alphabet are rejected as
+// soon as the byte is reached. It should be constructed only via
+// MakeGenericByteClassTrie or MakeByteClassTrie.
+type GenericByteClassTrie[I constraints.Unsigned] struct {
+	classes ByteClassTable
+	// The number of I-sized words in each node. Each node consists of the
+	// keyword index slot (0 for none or 1 + i for keyword index i) followed by
+	// a child slot for each class. As for GenericTrie, node 0 is the nowhere
+	// node and node 1 is the root.
+	nodeSize     int
+	backingSlice []I
+}
+
+// ComputeByteClasses returns a ByteClassTable that assigns a distinct class to
+// each byte that occurs in keywords. It returns false if more than 255
+// distinct bytes occur.
+func ComputeByteClasses[T ByteIndexable](keywords []T) (ByteClassTable, bool) {
+	var seen [256]bool
+	for _, k := range keywords {
+		for i := 0; i < len(k); i++ {
+			seen[k[i]] = true
+		}
+	}
+
+	var table ByteClassTable
+	n := 0
+	for b, s := range seen {
+		if s {
+			if n == 255 {
+				return ByteClassTable{}, false
+			}
+			n++
+			table[b] = uint8(n)
+		}
+	}
+	return table, true
+}
+
+// MakeByteClassTrie calls MakeGenericByteClassTrie with the I type parameter
+// set to uint16 (the recommended default).
+func MakeByteClassTrie[T ByteIndexable](keywords []T, classes *ByteClassTable) (ByteClassTrie, bool) {
+	return MakeGenericByteClassTrie[uint16](keywords, classes)
+}
+
+// MakeGenericByteClassTrie constructs a byte class trie from a set of
+// keywords. If classes is nil, the table returned by ComputeByteClasses is
+// used. Otherwise every byte of every keyword must have a non-zero class in
+// classes. A caller-supplied table may map several bytes to the same class
+// (e.g. to match keywords case insensitively). The second return value is
+// false if the trie could not be constructed, in which case the returned trie
+// is empty.
+func MakeGenericByteClassTrie[I constraints.Unsigned, T ByteIndexable](keywords []T, classes *ByteClassTable) (GenericByteClassTrie[I], bool) {
+	var trie GenericByteClassTrie[I]
+
+	if classes == nil {
+		table, ok := ComputeByteClasses(keywords)
+		if !ok {
+			return makeEmptyByteClassTrie[I](), false
+		}
+		trie.classes = table
+	} else {
+		trie.classes = *classes
+	}
+
+	nClasses := 0
+	for _, c := range trie.classes {
+		nClasses = max(nClasses, int(c))
+	}
+	trie.nodeSize = nClasses + 1
+	trie.backingSlice = make([]I, trie.nodeSize*2)
+
+	maxI := maxElem[I]()
+
+	for wi, k := range keywords {
+		if wi+1 >= maxI {
+			return makeEmptyByteClassTrie[I](), false
+		}
+
+		off := 1
+		for i := 0; i < len(k); i++ {
+			c := int(trie.classes[k[i]])
+			if c == 0 {
+				return makeEmptyByteClassTrie[I](), false
+			}
+
+			childIndexI := off*trie.nodeSize + c
+			if trie.backingSlice[childIndexI] == 0 {
+				n := len(trie.backingSlice) / trie.nodeSize
+				if n >= maxI {
+					return makeEmptyByteClassTrie[I](), false
+				}
+				trie.backingSlice[childIndexI] = I(n)
+				trie.backingSlice = append(trie.backingSlice, make([]I, trie.nodeSize)...)
+				off = n
+			} else {
+				off = int(trie.backingSlice[childIndexI])
+			}
+		}
+
+		if len(k) > 0 {
+			trie.backingSlice[off*trie.nodeSize] = I(wi + 1)
+		}
+	}
+
+	return trie, true
+}
+
+func makeEmptyByteClassTrie[I constraints.Unsigned]() GenericByteClassTrie[I] {
+	return GenericByteClassTrie[I]{nodeSize: 1, backingSlice: make([]I, 2)}
+}
+
+// ByteClassKeywordIndex returns the index of word in the list of keywords
+// passed to MakeByteClassTrie/MakeGenericByteClassTrie, or -1 if it is not
+// present.
+func ByteClassKeywordIndex[T ByteIndexable, I constraints.Unsigned](trie *GenericByteClassTrie[I], word T) int {
+	ba := trie.backingSlice
+	ns := trie.nodeSize
+
+	off := 1
+	for i := 0; i < len(word); i++ {
+		c := int(trie.classes[word[i]])
+		if c == 0 {
+			return -1
+		}
+		off = int(ba[off*ns+c])
+		if off == 0 {
+			return -1
+		}
+	}
+
+	return int(ba[off*ns]) - 1
+}
@@ -0,0 +1,108 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestByteClassTrie(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+	bct, ok := MakeByteClassTrie(td.Keywords, nil)
+	if !ok {
+		t.Fatalf("Expecting byte class trie to be constructed successfully")
+	}
+
+	for _, w := range append(td.ToTest, "", "A", "zzzzzzzzzzzz", "\xff", "a1") {
+		if ByteClassKeywordIndex(&bct, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, ByteClassKeywordIndex(&bct, w))
+		}
+		if ByteClassKeywordIndex(&bct, []byte(w)) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v' (as byte slice)", KeywordIndex(trie, w), w)
+		}
+	}
+
+	if len(bct.backingSlice) >= len(trie.backingSlice) {
+		t.Errorf("Expecting byte class trie (%v) to be smaller than trie (%v)", len(bct.backingSlice), len(trie.backingSlice))
+	}
+}
+
+func TestComputeByteClasses(t *testing.T) {
+	table, ok := ComputeByteClasses([]string{"cab", "b"})
+	if !ok {
+		t.Fatalf("Expecting byte classes to be computed successfully")
+	}
+	if table['a'] != 1 || table['b'] != 2 || table['c'] != 3 || table['d'] != 0 {
+		t.Errorf("Unexpected byte classes: a=%v b=%v c=%v d=%v", table['a'], table['b'], table['c'], table['d'])
+	}
+
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	if _, ok := ComputeByteClasses([][]byte{all}); ok {
+		t.Errorf("Expecting too many distinct bytes")
+	}
+	if _, ok := MakeByteClassTrie([][]byte{all}, nil); ok {
+		t.Errorf("Expecting byte class trie to fail to be constructed")
+	}
+}
+
+func TestByteClassTrieWithSuppliedTable(t *testing.T) {
+	// case insensitive matching of ASCII letters
+	var table ByteClassTable
+	for i := 0; i < 26; i++ {
+		table['a'+i] = uint8(i + 1)
+		table['A'+i] = uint8(i + 1)
+	}
+
+	bct, ok := MakeByteClassTrie([]string{"select", "from"}, &table)
+	if !ok {
+		t.Fatalf("Expecting byte class trie to be constructed successfully")
+	}
+	for w, i := range map[string]int{"SELECT": 0, "Select": 0, "from": 1, "FROM": 1, "fro": -1, "from_": -1, "": -1} {
+		if ByteClassKeywordIndex(&bct, w) != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, w, ByteClassKeywordIndex(&bct, w))
+		}
+	}
+
+	if _, ok := MakeByteClassTrie([]string{"select_all"}, &table); ok {
+		t.Errorf("Expecting construction to fail for keyword outside alphabet")
+	}
+}
+
+func TestByteClassTrieTooBig(t *testing.T) {
+	_, ok := MakeByteClassTrie(getIdentifiers(20000), nil)
+	if ok {
+		t.Errorf("Expecting byte class trie to fail to be constructed")
+	}
+	bct, ok := MakeGenericByteClassTrie[uint32](getIdentifiers(20000), nil)
+	if !ok {
+		t.Fatalf("Expecting uint32 byte class trie to be constructed successfully")
+	}
+	for _, w := range getIdentifiers(100) {
+		if ByteClassKeywordIndex(&bct, w) == -1 {
+			t.Errorf("Expecting to find '%v'", w)
+		}
+	}
+}
+
+func BenchmarkRandomByteClassTrie(b *testing.B) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie, ok := MakeByteClassTrie(td.Keywords, nil)
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (ByteClassKeywordIndex(&trie, w) != -1) != td.InTrie[j] {
+				panic("Internal error [37] in benchmark")
+			}
+		}
+	}
+
+	b.ReportMetric(float64(len(trie.backingSlice)*2), "trie-bytes")
+}
@@ -0,0 +1,60 @@
+package keywordmap
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Equal returns true if a and b contain the same keywords with the same
+// indices. Tries may be equal even if their backing slices differ (e.g. because
+// their keywords were added in a different order).
+func Equal[I constraints.Unsigned](a, b GenericTrie[I]) bool {
+	equal := true
+	walkPair(a.backingSlice, b.backingSlice, func(_ []byte, ai, bi int) bool {
+		equal = ai == bi
+		return equal
+	})
+	return equal
+}
+
+// Canonicalize returns a trie with the same keywords and indices as trie whose
+// backing slice depends only on its contents. That is, Canonicalize(a) and
+// Canonicalize(b) have identical backing slices whenever Equal(a, b).
+func Canonicalize[I constraints.Unsigned](trie GenericTrie[I]) GenericTrie[I] {
+	// Adding the keywords in byte order determines the node numbering. The
+	// canonical trie never has more nodes than the original, so AddToTrie
+	// cannot fail.
+	result := MakeEmptyTrie[I]()
+	walk(trie.backingSlice, func(keyword []byte, i int) bool {
+		AddToTrie(&result, keyword, i)
+		return true
+	})
+	return result
+}
+
+// EncodeCanonical returns the little-endian encoding (see EncodeTrie) of the
+// canonical form of trie. The result is identical for equal tries, so it is
+// suitable for writing generated trie files. It can be passed to OpenTrieView.
+func EncodeCanonical[I constraints.Unsigned](trie GenericTrie[I]) []byte {
+	return EncodeTrie(Canonicalize(trie))
+}
+
+// Hash returns a 64-bit hash of the keywords and indices in trie. The hash is
+// equal for equal tries, and does not depend on I. It is computed using a fixed
+// algorithm (64-bit FNV-1a over the length, bytes and index of each keyword in
+// byte order, with the length and index encoded as uvarints). It is therefore
+// stable across program runs and versions of this package, and may be stored
+// to check whether a generated trie is out of date.
+func Hash[I constraints.Unsigned](trie GenericTrie[I]) uint64 {
+	h := fnv.New64a()
+	var buf [binary.MaxVarintLen64]byte
+	walk(trie.backingSlice, func(keyword []byte, i int) bool {
+		h.Write(binary.AppendUvarint(buf[:0], uint64(len(keyword))))
+		h.Write(keyword)
+		h.Write(binary.AppendUvarint(buf[:0], uint64(i)))
+		return true
+	})
+	return h.Sum64()
+}
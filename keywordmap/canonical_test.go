@@ -0,0 +1,89 @@
+package keywordmap
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestEqualAndCanonicalize(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	a := MakeEmptyTrie[uint16]()
+	b := MakeEmptyTrie[uint16]()
+	for i, k := range td.Keywords {
+		AddToTrie(&a, k, i)
+	}
+	for i := len(td.Keywords) - 1; i >= 0; i-- {
+		AddToTrie(&b, td.Keywords[i], i)
+	}
+
+	if slices.Equal(a.backingSlice, b.backingSlice) {
+		t.Fatalf("Expecting backing slices to differ")
+	}
+	if !Equal(a, b) {
+		t.Errorf("Expecting tries to be equal")
+	}
+	if !bytes.Equal(EncodeCanonical(a), EncodeCanonical(b)) {
+		t.Errorf("Expecting canonical encodings to be identical")
+	}
+	if Hash(a) != Hash(b) {
+		t.Errorf("Expecting hashes to be identical")
+	}
+
+	c := Canonicalize(a)
+	for _, w := range td.ToTest {
+		if KeywordIndex(c, w) != KeywordIndex(a, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(a, w), w, KeywordIndex(c, w))
+		}
+	}
+}
+
+func TestNotEqual(t *testing.T) {
+	base := mustMakeTrie(t, []string{"for", "form", "if"})
+	for _, other := range []Trie{
+		mustMakeTrie(t, []string{"for", "form"}),
+		mustMakeTrie(t, []string{"for", "form", "if", "fo"}),
+		mustMakeTrie(t, []string{"for", "if", "form"}),
+		MakeEmptyTrie[uint16](),
+	} {
+		if Equal(base, other) || Equal(other, base) {
+			t.Errorf("Expecting tries not to be equal")
+		}
+		if Hash(base) == Hash(other) {
+			t.Errorf("Expecting hashes to differ")
+		}
+		if bytes.Equal(EncodeCanonical(base), EncodeCanonical(other)) {
+			t.Errorf("Expecting canonical encodings to differ")
+		}
+	}
+}
+
+func TestCanonicalizeDropsDanglingNodes(t *testing.T) {
+	trie, _ := MakeGenericTrie[uint8]([]string{"to"})
+	clean, _ := MakeGenericTrie[uint8]([]string{"to"})
+	// A failed AddToTrie can leave nodes with no keyword behind.
+	if AddToTrie(&trie, "abcdefgh", 1) {
+		t.Fatalf("Expecting AddToTrie to fail")
+	}
+	if len(trie.backingSlice) == len(clean.backingSlice) {
+		t.Fatalf("Expecting AddToTrie to leave dangling nodes")
+	}
+	if !Equal(trie, clean) {
+		t.Errorf("Expecting tries to be equal")
+	}
+	if !slices.Equal(Canonicalize(trie).backingSlice, clean.backingSlice) {
+		t.Errorf("Expecting canonical trie to have no dangling nodes")
+	}
+}
+
+func TestHashIsStable(t *testing.T) {
+	trie16 := mustMakeTrie(t, []string{"debu", "with", "and", "for", "case", "to", "form"})
+	trie32, _ := MakeGenericTrie[uint32]([]string{"debu", "with", "and", "for", "case", "to", "form"})
+
+	// This value must not change between versions of the package.
+	const expected = 0x5af144cf95eac33f
+	if Hash(trie16) != expected || Hash(trie32) != expected {
+		t.Errorf("Expecting hash %#x, got %#x and %#x", uint64(expected), Hash(trie16), Hash(trie32))
+	}
+}
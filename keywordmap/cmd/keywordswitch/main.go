@@ -0,0 +1,37 @@
+// Command keywordswitch generates a Go function that maps keywords to indices
+// using switch statements (see keywordmap.GenerateSwitch). The keywords are
+// given as arguments, and are assigned indices in the order given. For example:
+//
+//	//go:generate keywordswitch -pkg mylang -func keywordIndex -o keywords_gen.go if else for
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/addrummond/deckwreck/keywordmap"
+)
+
+func main() {
+	pkg := flag.String("pkg", "main", "package name for the generated file")
+	funcName := flag.String("func", "keywordIndex", "name of the generated function")
+	out := flag.String("o", "", "output file (default standard output)")
+	flag.Parse()
+
+	var b bytes.Buffer
+	if err := keywordmap.GenerateSwitch(&b, *pkg, *funcName, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "keywordswitch: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(b.Bytes())
+		return
+	}
+	if err := os.WriteFile(*out, b.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "keywordswitch: %v\n", err)
+		os.Exit(1)
+	}
+}
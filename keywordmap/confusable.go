@@ -0,0 +1,161 @@
+package keywordmap
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/exp/constraints"
+)
+
+// confusables maps characters to their skeletons. It is a subset of the
+// Unicode confusables table (UTS #39, confusables.txt): only entries that map
+// a character to a sequence of ASCII characters are included, and only for
+// the characters most likely to be used to spoof ASCII keywords (non-ASCII
+// Latin, Cyrillic and Greek homoglyphs of ASCII letters, and a few ASCII
+// characters that are confusable with each other). The one exception is the
+// entry for 'm', which reverses the table's mapping of "rn" to "m", as only
+// single characters can be mapped here. Fullwidth ASCII characters are handled
+// separately by skeletonOf.
+var confusables = map[rune]string{
+	// ASCII
+	'0': "O",
+	'1': "l",
+	'I': "l",
+	'|': "l",
+	'm': "rn",
+
+	// Latin
+	'ɡ': "g", 'ı': "i", 'ȷ': "j",
+
+	// Cyrillic
+	'а': "a", 'е': "e", 'о': "o", 'р': "p", 'с': "c", 'у': "y",
+	'х': "x", 'ѕ': "s", 'і': "i", 'ј': "j", 'һ': "h", 'ԁ': "d", 'ԛ': "q",
+	'ԝ': "w", 'ӏ': "l", 'ү': "y", 'ѵ': "v",
+	'А': "A", 'В': "B", 'Е': "E", 'К': "K", 'М': "M", 'Н': "H", 'О': "O",
+	'Р': "P", 'С': "C", 'Т': "T", 'Х': "X", 'Ѕ': "S", 'І': "l", 'Ј': "J",
+	'Ү': "Y", 'Ԛ': "Q", 'Ԝ': "W", 'Ӏ': "l", 'З': "3",
+
+	// Greek
+	'α': "a", 'ο': "o", 'ρ': "p", 'ν': "v", 'ι': "i", 'γ': "y", 'σ': "o",
+	'Α': "A", 'Β': "B", 'Ε': "E", 'Ζ': "Z", 'Η': "H", 'Ι': "l", 'Κ': "K",
+	'Μ': "M", 'Ν': "N", 'Ο': "O", 'Ρ': "P", 'Τ': "T", 'Υ': "Y", 'Χ': "X",
+}
+
+// skeletonOf returns the skeleton of a single character. It returns the
+// empty string if the character is its own skeleton.
+func skeletonOf(r rune) string {
+	if s, ok := confusables[r]; ok {
+		return s
+	}
+	// Fullwidth forms of the printable ASCII characters.
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r -= 0xFF01 - 0x21
+		if s, ok := confusables[r]; ok {
+			return s
+		}
+		return asciiStrings[r]
+	}
+	return ""
+}
+
+var asciiStrings = func() (a [128]string) {
+	for i := range a {
+		a[i] = string(rune(i))
+	}
+	return
+}()
+
+// Skeleton maps s to its confusable skeleton: two strings that look alike have
+// the same skeleton. Each character is mapped independently using a subset of
+// the Unicode confusables table. (Unlike the full UTS #39 skeleton algorithm,
+// no Unicode normalization is performed.) Invalid UTF-8 sequences are left
+// unchanged.
+func Skeleton(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if sk := skeletonOf(r); sk != "" {
+			sb.WriteString(sk)
+		} else {
+			sb.WriteString(s[i : i+n])
+		}
+		i += n
+	}
+	return sb.String()
+}
+
+// ConfusableDetector is the recommended instantiation of
+// GenericConfusableDetector.
+type ConfusableDetector = GenericConfusableDetector[uint16]
+
+// GenericConfusableDetector detects inputs that look like keywords without
+// being keywords (e.g. "rеturn" with a Cyrillic 'е'). It stores the skeletons
+// of a set of keywords in a trie, together with a trie of the keywords
+// themselves. It should be constructed only via
+// MakeGenericConfusableDetector or MakeConfusableDetector.
+type GenericConfusableDetector[I constraints.Unsigned] struct {
+	skeletons GenericTrie[I]
+	keywords  GenericTrie[I]
+}
+
+// MakeConfusableDetector calls MakeGenericConfusableDetector with the I type
+// parameter set to uint16 (the recommended default).
+func MakeConfusableDetector[T ByteIndexable](keywords []T) (ConfusableDetector, bool) {
+	return MakeGenericConfusableDetector[uint16](keywords)
+}
+
+// MakeGenericConfusableDetector constructs a detector for a set of keywords.
+// The second return value is false if the trie of keyword skeletons could not
+// be constructed (see MakeGenericTrie). If several keywords have the same
+// skeleton, the last of them is reported.
+func MakeGenericConfusableDetector[I constraints.Unsigned, T ByteIndexable](keywords []T) (GenericConfusableDetector[I], bool) {
+	d := GenericConfusableDetector[I]{MakeEmptyTrie[I](), MakeEmptyTrie[I]()}
+	for i, k := range keywords {
+		if !AddToTrie(&d.skeletons, Skeleton(string(k)), i) || !AddToTrie(&d.keywords, k, i) {
+			return GenericConfusableDetector[I]{MakeEmptyTrie[I](), MakeEmptyTrie[I]()}, false
+		}
+	}
+	return d, true
+}
+
+// ConfusableKeywordIndex returns the index of the keyword that word looks like,
+// or -1 if word does not look like any keyword. A word that is itself a keyword
+// is not reported, even if it also looks like another keyword. The skeleton of
+// word is computed on the fly without allocating.
+func ConfusableKeywordIndex[T ByteIndexable, I constraints.Unsigned](d *GenericConfusableDetector[I], word T) int {
+	ba := d.skeletons.backingSlice
+
+	off := 1
+	for i := 0; i < len(word); {
+		var r rune
+		n := 1
+		if word[i] < utf8.RuneSelf {
+			r = rune(word[i])
+		} else {
+			var buf [utf8.UTFMax]byte
+			k := copy(buf[:], word[i:min(i+utf8.UTFMax, len(word))])
+			r, n = utf8.DecodeRune(buf[:k])
+		}
+
+		if sk := skeletonOf(r); sk != "" {
+			for j := 0; j < len(sk); j++ {
+				off = stepByte(ba, off, sk[j])
+			}
+		} else {
+			for j := i; j < i+n; j++ {
+				off = stepByte(ba, off, word[j])
+			}
+		}
+		if off == 0 {
+			return -1
+		}
+
+		i += n
+	}
+
+	ki := int(ba[off*nodeSize+nodeSize-1]) - 1
+	if ki == -1 || KeywordIndex(d.keywords, word) != -1 {
+		return -1
+	}
+	return ki
+}
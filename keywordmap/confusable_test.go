@@ -0,0 +1,80 @@
+package keywordmap
+
+import "testing"
+
+func TestSkeleton(t *testing.T) {
+	for in, out := range map[string]string{
+		"return":  "return",
+		"rеturn":  "return",
+		"ｒｅｔｕｒｎ":  "return",
+		"import":  "irnport",
+		"irnport": "irnport",
+		"f0r":     "fOr",
+		"\xff":    "\xff",
+		"日本":      "日本",
+	} {
+		if Skeleton(in) != out {
+			t.Errorf("Expecting skeleton of %q to be %q, got %q", in, out, Skeleton(in))
+		}
+	}
+}
+
+func TestConfusableKeywordIndex(t *testing.T) {
+	d, ok := MakeConfusableDetector(goKeywords)
+	if !ok {
+		t.Fatalf("Expecting detector to be constructed successfully")
+	}
+	index := func(k string) int {
+		for i, kw := range goKeywords {
+			if kw == k {
+				return i
+			}
+		}
+		panic("not a keyword")
+	}
+
+	for w, i := range map[string]int{
+		"rеturn":     index("return"), // Cyrillic е
+		"rеturn\xff": -1,
+		"ｒｅｔｕｒｎ":     index("return"),
+		"irnport":    index("import"),
+		"ѕеlеct":     index("select"),
+		"fоr":        index("for"), // Cyrillic о
+		"іf":         index("if"),
+		"return":     -1,
+		"returns":    -1,
+		"retur":      -1,
+		"foo":        -1,
+		"":           -1,
+		"日本":         -1,
+	} {
+		if got := ConfusableKeywordIndex(&d, w); got != i {
+			t.Errorf("Expecting %v for %q, got %v", i, w, got)
+		}
+		if got := ConfusableKeywordIndex(&d, []byte(w)); got != i {
+			t.Errorf("Expecting %v for %q (as byte slice), got %v", i, w, got)
+		}
+	}
+}
+
+func TestConfusableKeywordIndexDoesNotReportKeywords(t *testing.T) {
+	// "l1" and "ll" have the same skeleton, but both are keywords.
+	d, ok := MakeConfusableDetector([]string{"l1", "ll"})
+	if !ok {
+		t.Fatalf("Expecting detector to be constructed successfully")
+	}
+	if ConfusableKeywordIndex(&d, "l1") != -1 || ConfusableKeywordIndex(&d, "ll") != -1 {
+		t.Errorf("Did not expect keywords to be reported")
+	}
+	if ConfusableKeywordIndex(&d, "1l") != 1 {
+		t.Errorf("Expecting '1l' to look like 'll'")
+	}
+}
+
+func TestConfusableKeywordIndexDoesNotAllocate(t *testing.T) {
+	d, _ := MakeConfusableDetector(goKeywords)
+	w := []byte("rеturn")
+	if allocs := testing.AllocsPerRun(100, func() { ConfusableKeywordIndex(&d, w) }); allocs != 0 {
+		t.Errorf("Expecting no allocations, got %v", allocs)
+	}
+}
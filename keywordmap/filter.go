@@ -0,0 +1,68 @@
+package keywordmap
+
+import "golang.org/x/exp/constraints"
+
+// FilteredTrie is the recommended instantiation of GenericFilteredTrie.
+type FilteredTrie = GenericFilteredTrie[uint16]
+
+// GenericFilteredTrie is a GenericTrie together with a cheap prefilter that
+// rejects most non-keywords without touching the trie's backing array. The
+// prefilter checks the length of the input against the lengths of the shortest
+// and longest keywords, and the first byte of the input against the set of
+// bytes that begin a keyword. It should be constructed only via
+// MakeGenericFilteredTrie, MakeFilteredTrie or FilterTrie.
+type GenericFilteredTrie[I constraints.Unsigned] struct {
+	trie           GenericTrie[I]
+	minLen, maxLen int
+	firstBytes     [4]uint64
+}
+
+// MakeFilteredTrie calls MakeGenericFilteredTrie with the I type parameter set
+// to uint16 (the recommended default).
+func MakeFilteredTrie[T ByteIndexable](keywords []T) (FilteredTrie, bool) {
+	return MakeGenericFilteredTrie[uint16](keywords)
+}
+
+// MakeGenericFilteredTrie constructs a filtered trie from a set of keywords. It
+// behaves like MakeGenericTrie.
+func MakeGenericFilteredTrie[I constraints.Unsigned, T ByteIndexable](keywords []T) (GenericFilteredTrie[I], bool) {
+	trie, ok := MakeGenericTrie[I](keywords)
+	return FilterTrie(trie), ok
+}
+
+// FilterTrie computes a prefilter for an existing trie. The trie should not be
+// modified (e.g. using AddToTrie) after the prefilter has been computed.
+func FilterTrie[I constraints.Unsigned](trie GenericTrie[I]) GenericFilteredTrie[I] {
+	ft := GenericFilteredTrie[I]{trie: trie, minLen: -1, maxLen: -1}
+	walk(trie.backingSlice, func(keyword []byte, _ int) bool {
+		if ft.minLen == -1 || len(keyword) < ft.minLen {
+			ft.minLen = len(keyword)
+		}
+		if len(keyword) > ft.maxLen {
+			ft.maxLen = len(keyword)
+		}
+		ft.firstBytes[keyword[0]>>6] |= 1 << (keyword[0] & 63)
+		return true
+	})
+	return ft
+}
+
+// FilteredKeywordIndex returns the index of word in the list of keywords passed
+// to MakeFilteredTrie/MakeGenericFilteredTrie, or -1 if it is not present.
+func FilteredKeywordIndex[T ByteIndexable, I constraints.Unsigned](trie GenericFilteredTrie[I], word T) int {
+	// A single unsigned comparison checks both length bounds. An empty trie has
+	// minLen == maxLen == -1, so every word is rejected here. Otherwise minLen
+	// is at least 1, so the empty string is rejected.
+	if uint(len(word)-trie.minLen) > uint(trie.maxLen-trie.minLen) {
+		return -1
+	}
+	if trie.firstBytes[word[0]>>6]&(1<<(word[0]&63)) == 0 {
+		return -1
+	}
+	return KeywordIndex(trie.trie, word)
+}
+
+// Unfiltered returns the trie underlying a filtered trie.
+func Unfiltered[I constraints.Unsigned](trie GenericFilteredTrie[I]) GenericTrie[I] {
+	return trie.trie
+}
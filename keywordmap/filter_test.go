@@ -0,0 +1,117 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var goKeywords = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else",
+	"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+	"map", "package", "range", "return", "select", "struct", "switch", "type",
+	"var",
+}
+
+func TestFilteredTrie(t *testing.T) {
+	trie := mustMakeTrie(t, goKeywords)
+	ft, ok := MakeFilteredTrie(goKeywords)
+	if !ok {
+		t.Fatalf("Expecting filtered trie to be constructed successfully")
+	}
+
+	if ft.minLen != 2 || ft.maxLen != 11 {
+		t.Errorf("Expecting length bounds [2, 11], got [%v, %v]", ft.minLen, ft.maxLen)
+	}
+
+	inputs := append([]string{"", "g", "x", "gox", "fallthroughs", "zzz", "_", "\xff"}, goKeywords...)
+	inputs = append(inputs, getIdentifiers(1000)...)
+	for _, w := range inputs {
+		if FilteredKeywordIndex(ft, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, FilteredKeywordIndex(ft, w))
+		}
+		if FilteredKeywordIndex(ft, []byte(w)) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v' (as byte slice)", KeywordIndex(trie, w), w)
+		}
+	}
+}
+
+func TestFilteredEmptyTrie(t *testing.T) {
+	ft := FilterTrie(MakeEmptyTrie[uint16]())
+	for _, w := range []string{"", "a", "foo"} {
+		if FilteredKeywordIndex(ft, w) != -1 {
+			t.Errorf("Did not expect to find '%v' in empty trie", w)
+		}
+	}
+}
+
+// getIdentifierMix returns a mixture of identifiers in which roughly one in
+// five is a keyword, which is a reasonable approximation of real code.
+func getIdentifierMix(keywords []string) []string {
+	r := rand.New(rand.NewSource(randSeed))
+	ids := getIdentifiers(1000)
+	for i := range ids {
+		if r.Intn(5) == 0 {
+			ids[i] = keywords[r.Intn(len(keywords))]
+		}
+	}
+	return ids
+}
+
+func BenchmarkRandomFilteredTrie(b *testing.B) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie, ok := MakeFilteredTrie(td.Keywords)
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (FilteredKeywordIndex(trie, w) != -1) != td.InTrie[j] {
+				panic("Internal error [22] in benchmark")
+			}
+		}
+	}
+}
+
+func BenchmarkIdentifierMixTrie(b *testing.B) {
+	ids := getIdentifierMix(goKeywords)
+	trie := mustMakeTrie(b, goKeywords)
+
+	b.ResetTimer()
+
+	n := 0
+	for i := 0; i < b.N; i++ {
+		for _, w := range ids {
+			if KeywordIndex(trie, w) != -1 {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		panic("Internal error [23] in benchmark")
+	}
+}
+
+func BenchmarkIdentifierMixFilteredTrie(b *testing.B) {
+	ids := getIdentifierMix(goKeywords)
+	trie, ok := MakeFilteredTrie(goKeywords)
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	n := 0
+	for i := 0; i < b.N; i++ {
+		for _, w := range ids {
+			if FilteredKeywordIndex(trie, w) != -1 {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		panic("Internal error [24] in benchmark")
+	}
+}
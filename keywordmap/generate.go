@@ -0,0 +1,96 @@
+package keywordmap
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// GenerateSwitch writes Go source code for a function that maps keywords to
+// indices using nested switch statements on the length and first byte of its
+// input. The generated function has the signature
+//
+//	func funcName(s string) int
+//
+// and the same contract as KeywordIndex for a trie constructed from keywords.
+// For very small sets of keywords it may be faster than a trie. If packageName
+// is non-empty, a complete source file (with a "Code generated" header and a
+// package clause) is written. Otherwise only the function is written.
+func GenerateSwitch[T ByteIndexable](w io.Writer, packageName, funcName string, keywords []T) error {
+	// As with AddToTrie, a repeated keyword takes the index of its last
+	// occurrence and the empty keyword is ignored.
+	indices := make(map[string]int)
+	for i, k := range keywords {
+		if len(k) > 0 {
+			indices[string(k)] = i
+		}
+	}
+
+	groups := make(map[int]map[byte][]string)
+	for k := range indices {
+		if groups[len(k)] == nil {
+			groups[len(k)] = make(map[byte][]string)
+		}
+		groups[len(k)][k[0]] = append(groups[len(k)][k[0]], k)
+	}
+
+	var b bytes.Buffer
+	if packageName != "" {
+		fmt.Fprintf(&b, "// Code generated by keywordmap.GenerateSwitch. DO NOT EDIT.\n\npackage %s\n\n", packageName)
+	}
+	fmt.Fprintf(&b, "// %s returns the index of s in the list of keywords that it was generated\n// from, or -1 if s is not present.\n", funcName)
+	fmt.Fprintf(&b, "func %s(s string) int {\n", funcName)
+	if len(groups) > 0 {
+		b.WriteString("switch len(s) {\n")
+		for _, l := range sortedKeys(groups) {
+			fmt.Fprintf(&b, "case %d:\n", l)
+			b.WriteString("switch s[0] {\n")
+			for _, c := range sortedKeys(groups[l]) {
+				ks := groups[l][c]
+				slices.Sort(ks)
+				fmt.Fprintf(&b, "case %s:\n", byteLiteral(c))
+				switch {
+				case l == 1:
+					fmt.Fprintf(&b, "return %d\n", indices[ks[0]])
+				case len(ks) == 1:
+					fmt.Fprintf(&b, "if s == %s {\nreturn %d\n}\n", strconv.Quote(ks[0]), indices[ks[0]])
+				default:
+					b.WriteString("switch s {\n")
+					for _, k := range ks {
+						fmt.Fprintf(&b, "case %s:\nreturn %d\n", strconv.Quote(k), indices[k])
+					}
+					b.WriteString("}\n")
+				}
+			}
+			b.WriteString("}\n")
+		}
+		b.WriteString("}\n")
+	}
+	b.WriteString("return -1\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func sortedKeys[K int | byte, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func byteLiteral(c byte) string {
+	if c >= 0x20 && c < 0x7F {
+		return strconv.QuoteRune(rune(c))
+	}
+	return fmt.Sprintf("0x%02x", c)
+}
@@ -0,0 +1,109 @@
+package keywordmap
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+//go:generate go run ./cmd/keywordswitch -pkg keywordmap -func goKeywordSwitch -o switch_generated_test.go break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var
+
+func TestGeneratedSwitchUpToDate(t *testing.T) {
+	var b bytes.Buffer
+	if err := GenerateSwitch(&b, "keywordmap", "goKeywordSwitch", goKeywords); err != nil {
+		t.Fatalf("Expecting switch to be generated successfully, got %v", err)
+	}
+	existing, err := os.ReadFile("switch_generated_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), existing) {
+		t.Errorf("Expecting switch_generated_test.go to be up to date (run go generate)")
+	}
+}
+
+func TestGeneratedSwitchMatchesTrie(t *testing.T) {
+	trie := mustMakeTrie(t, goKeywords)
+
+	r := rand.New(rand.NewSource(randSeed))
+	inputs := append([]string{""}, getIdentifierMix(goKeywords)...)
+	for _, k := range goKeywords {
+		inputs = append(inputs, k, k[:len(k)-1], k+"x", strings.ToUpper(k))
+		for i := 0; i < 10; i++ {
+			mutated := []byte(k)
+			mutated[r.Intn(len(mutated))] = byte(r.Intn(256))
+			inputs = append(inputs, string(mutated))
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		random := make([]byte, r.Intn(12))
+		r.Read(random)
+		inputs = append(inputs, string(random))
+	}
+
+	for _, w := range inputs {
+		if goKeywordSwitch(w) != KeywordIndex(trie, w) {
+			t.Errorf("%q: expecting %v, got %v", w, KeywordIndex(trie, w), goKeywordSwitch(w))
+		}
+	}
+}
+
+func TestGenerateSwitchEdgeCases(t *testing.T) {
+	cases := []struct {
+		keywords []string
+		expected []string
+	}{
+		{nil, []string{"return -1"}},
+		{[]string{""}, []string{"return -1"}},
+		{[]string{"a", "b"}, []string{"case 'a':\n\t\t\treturn 0", "case 'b':\n\t\t\treturn 1"}},
+		{[]string{"x", "x"}, []string{"case 'x':\n\t\t\treturn 1"}},
+		{[]string{"\xff\x00", "'\\"}, []string{"case 0xff:", `if s == "\xff\x00"`, `case '\'':`, `if s == "'\\"`}},
+	}
+	for _, c := range cases {
+		var b bytes.Buffer
+		if err := GenerateSwitch(&b, "", "f", c.keywords); err != nil {
+			t.Errorf("%q: expecting switch to be generated successfully, got %v", c.keywords, err)
+			continue
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+b.String(), 0); err != nil {
+			t.Errorf("%q: expecting generated code to parse, got %v", c.keywords, err)
+		}
+		for _, e := range c.expected {
+			if !strings.Contains(b.String(), e) {
+				t.Errorf("%q: expecting generated code to contain %q, got\n%v", c.keywords, e, b.String())
+			}
+		}
+	}
+}
+
+func TestGenerateSwitchBadName(t *testing.T) {
+	var b bytes.Buffer
+	if GenerateSwitch(&b, "", "not a name", goKeywords) == nil {
+		t.Errorf("Expecting generation to fail for an invalid function name")
+	}
+	if b.Len() != 0 {
+		t.Errorf("Expecting nothing to be written on failure")
+	}
+}
+
+func BenchmarkIdentifierMixSwitch(b *testing.B) {
+	ids := getIdentifierMix(goKeywords)
+
+	b.ResetTimer()
+
+	n := 0
+	for i := 0; i < b.N; i++ {
+		for _, w := range ids {
+			if goKeywordSwitch(w) != -1 {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		panic("Internal error [38] in benchmark")
+	}
+}
@@ -0,0 +1,212 @@
+package keywordmap
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+
+	"golang.org/x/exp/constraints"
+)
+
+// ErrBadPattern is returned (wrapped) by Glob if its pattern is malformed.
+var ErrBadPattern = errors.New("syntax error in glob pattern")
+
+// globElem is a single element of a compiled glob pattern: either a star or a
+// set of bytes that matches a single byte.
+type globElem struct {
+	star bool
+	set  [4]uint64
+}
+
+func (e *globElem) add(b byte) {
+	e.set[b>>6] |= 1 << (b & 63)
+}
+
+func (e *globElem) matches(b byte) bool {
+	return e.set[b>>6]&(1<<(b&63)) != 0
+}
+
+// Glob returns a sequence of the keywords in trie that match pattern, together
+// with their indices, in byte order. The pattern syntax is as follows:
+//
+//	'*'         matches any sequence of bytes
+//	'?'         matches any single byte
+//	'[' [ '!' | '^' ] { range } ']'
+//	            matches a single byte in (or with '!' or '^', not in) one of
+//	            the ranges, where a range is either a byte c or c1 '-' c2
+//	            (matching any byte between c1 and c2 inclusive)
+//	'\' c       matches the byte c
+//	c           matches the byte c, where c is not '*', '?', '[' or '\'
+//
+// Within a character class, '\' also escapes the following byte, and ']' is
+// taken literally if it comes first. As elsewhere in this package, patterns and
+// keywords are treated as sequences of bytes, so '?' matches a single byte of
+// a multibyte UTF-8 sequence. The trie is walked depth first, and subtrees that
+// cannot contain a match are skipped. An error wrapping ErrBadPattern is
+// returned if pattern is malformed.
+func Glob[I constraints.Unsigned](trie GenericTrie[I], pattern string) (iter.Seq2[string, int], error) {
+	elems, err := compileGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(string, int) bool) {
+		states := make([]bool, len(elems)+1)
+		addGlobState(elems, states, 0)
+		globWalk(trie.backingSlice, elems, 1, states, make([]byte, 0, 16), yield)
+	}, nil
+}
+
+func compileGlob(pattern string) ([]globElem, error) {
+	var elems []globElem
+	for i := 0; i < len(pattern); i++ {
+		var e globElem
+		switch c := pattern[i]; c {
+		case '*':
+			if len(elems) > 0 && elems[len(elems)-1].star {
+				continue
+			}
+			e.star = true
+		case '?':
+			e.set = [4]uint64{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}
+		case '\\':
+			i++
+			if i == len(pattern) {
+				return nil, globError(pattern, "trailing backslash")
+			}
+			e.add(pattern[i])
+		case '[':
+			j, err := compileGlobClass(pattern, i+1, &e)
+			if err != nil {
+				return nil, err
+			}
+			i = j
+		default:
+			e.add(c)
+		}
+		elems = append(elems, e)
+	}
+	return elems, nil
+}
+
+// compileGlobClass adds the bytes in the character class starting at
+// pattern[start] (just after the '[') to e. It returns the position of the
+// closing ']'.
+func compileGlobClass(pattern string, start int, e *globElem) (int, error) {
+	i := start
+	negate := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+
+	first := true
+	for ; i < len(pattern); i++ {
+		if pattern[i] == ']' && !first {
+			if negate {
+				for k := range e.set {
+					e.set[k] = ^e.set[k]
+				}
+			}
+			return i, nil
+		}
+		first = false
+
+		lo, ok := globClassByte(pattern, &i)
+		if !ok {
+			break
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			i += 2
+			if hi, ok = globClassByte(pattern, &i); !ok {
+				break
+			}
+			if hi < lo {
+				return 0, globError(pattern, "invalid range in character class")
+			}
+		}
+		for b := int(lo); b <= int(hi); b++ {
+			e.add(byte(b))
+		}
+	}
+
+	return 0, globError(pattern, "unterminated character class")
+}
+
+// globClassByte returns the (possibly escaped) byte at pattern[*i], advancing
+// *i past the escape if necessary.
+func globClassByte(pattern string, i *int) (byte, bool) {
+	if pattern[*i] == '\\' {
+		*i++
+		if *i == len(pattern) {
+			return 0, false
+		}
+	}
+	return pattern[*i], true
+}
+
+func globError(pattern, msg string) error {
+	return fmt.Errorf("%w %q: %v", ErrBadPattern, pattern, msg)
+}
+
+// addGlobState marks position p of the pattern as active, along with every
+// position reachable from it by matching stars against the empty string.
+func addGlobState(elems []globElem, states []bool, p int) {
+	for ; !states[p]; p++ {
+		states[p] = true
+		if p == len(elems) || !elems[p].star {
+			return
+		}
+	}
+}
+
+// globWalk visits the node at off, whose keyword is buf and for which states
+// marks the active positions of the pattern. It returns false if the traversal
+// should stop.
+func globWalk[I constraints.Unsigned](ba []I, elems []globElem, off int, states []bool, buf []byte, yield func(string, int) bool) bool {
+	if states[len(elems)] {
+		if ki := int(ba[off*nodeSize+nodeSize-1]) - 1; ki != -1 && !yield(string(buf), ki) {
+			return false
+		}
+	}
+
+	var next []bool
+	for hi := 0; hi < 16; hi++ {
+		mid := int(ba[off*nodeSize+hi])
+		if mid == 0 {
+			continue
+		}
+		for lo := 0; lo < 16; lo++ {
+			child := int(ba[mid*nodeSize+lo])
+			if child == 0 {
+				continue
+			}
+
+			b := byte(hi<<4 | lo)
+			if next == nil {
+				next = make([]bool, len(states))
+			} else {
+				clear(next)
+			}
+			alive := false
+			for p, active := range states[:len(elems)] {
+				switch {
+				case !active:
+				case elems[p].star:
+					addGlobState(elems, next, p)
+					alive = true
+				case elems[p].matches(b):
+					addGlobState(elems, next, p+1)
+					alive = true
+				}
+			}
+
+			if alive && !globWalk(ba, elems, child, next, append(buf, b), yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
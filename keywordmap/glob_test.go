@@ -0,0 +1,127 @@
+package keywordmap
+
+import (
+	"errors"
+	"math/rand"
+	"path"
+	"slices"
+	"testing"
+)
+
+func collectGlob(t *testing.T, trie Trie, pattern string) []string {
+	seq, err := Glob(trie, pattern)
+	if err != nil {
+		t.Fatalf("%v: unexpected error %v", pattern, err)
+	}
+	var matches []string
+	for k, i := range seq {
+		if KeywordIndex(trie, k) != i {
+			t.Errorf("%v: expecting index %v for %v, got %v", pattern, KeywordIndex(trie, k), k, i)
+		}
+		matches = append(matches, k)
+	}
+	return matches
+}
+
+func TestGlob(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"int", "int8", "int16", "int32", "int64", "size_t", "ssize_t", "wchar_t", "char", "a*b", "a]b", "uint8"})
+
+	cases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"*_t", []string{"size_t", "ssize_t", "wchar_t"}},
+		{"int??", []string{"int16", "int32", "int64"}},
+		{"int?", []string{"int8"}},
+		{"int*", []string{"int", "int16", "int32", "int64", "int8"}},
+		{"*int*", []string{"int", "int16", "int32", "int64", "int8", "uint8"}},
+		{"int[1-3]*", []string{"int16", "int32"}},
+		{"int[!1-3]*", []string{"int64", "int8"}},
+		{"int[^1-3]*", []string{"int64", "int8"}},
+		{"[cw]*", []string{"char", "wchar_t"}},
+		{"a\\*b", []string{"a*b"}},
+		{"a[*]b", []string{"a*b"}},
+		{"a[]]b", []string{"a]b"}},
+		{"a[\\]]b", []string{"a]b"}},
+		{"*8", []string{"int8", "uint8"}},
+		{"**a**", []string{"a*b", "a]b", "char", "wchar_t"}},
+		{"*", []string{"a*b", "a]b", "char", "int", "int16", "int32", "int64", "int8", "size_t", "ssize_t", "uint8", "wchar_t"}},
+		{"", nil},
+		{"x*", nil},
+		{"int", []string{"int"}},
+	}
+	for _, c := range cases {
+		matches := collectGlob(t, trie, c.pattern)
+		if !slices.Equal(matches, c.expected) {
+			t.Errorf("%v: expecting %v, got %v", c.pattern, c.expected, matches)
+		}
+	}
+}
+
+func TestGlobBadPattern(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"a"})
+	for _, p := range []string{"[", "[a", "[]", "a\\", "[a\\", "[z-a]", "[!"} {
+		if _, err := Glob(trie, p); !errors.Is(err, ErrBadPattern) {
+			t.Errorf("%v: expecting ErrBadPattern, got %v", p, err)
+		}
+	}
+}
+
+func TestGlobStop(t *testing.T) {
+	trie := mustMakeTrie(t, goKeywords)
+	seq, err := Glob(trie, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for range seq {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("Expecting iteration to stop after 3 keywords, got %v", n)
+	}
+}
+
+func TestRandomGlob(t *testing.T) {
+	r := rand.New(rand.NewSource(randSeed))
+	td := getRandomTestData(r)
+	trie := mustMakeTrie(t, td.Keywords)
+
+	// The syntax of path.Match differs slightly (e.g. in the treatment of a
+	// leading ']' in a character class), so patterns that only one of them
+	// accepts are skipped.
+	const alphabet = "abcdefg*?[]!-"
+	for i := 0; i < 2000; i++ {
+		p := make([]byte, r.Intn(6)+1)
+		for j := range p {
+			p[j] = alphabet[r.Intn(len(alphabet))]
+		}
+		pattern := string(p)
+
+		seq, err := Glob(trie, pattern)
+		_, pathErr := path.Match(pattern, "")
+		if err != nil || pathErr != nil {
+			continue
+		}
+
+		var expected []string
+		for _, k := range td.Keywords {
+			if ok, _ := path.Match(pattern, k); ok {
+				expected = append(expected, k)
+			}
+		}
+		slices.Sort(expected)
+		expected = slices.Compact(expected)
+
+		var matches []string
+		for k := range seq {
+			matches = append(matches, k)
+		}
+		if !slices.Equal(matches, expected) {
+			t.Errorf("%v: expecting %v, got %v", pattern, expected, matches)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package keywordmap
+
+import (
+	"io"
+	"iter"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/exp/constraints"
+)
+
+// RuneKeywordIndex works like KeywordIndex except that word is given as a
+// slice of runes. The runes are encoded as UTF-8 on the fly without
+// allocating. Invalid runes are encoded as U+FFFD (as for a conversion from
+// []rune to string).
+func RuneKeywordIndex[I constraints.Unsigned](trie GenericTrie[I], word []rune) int {
+	ba := trie.backingSlice
+
+	var buf [utf8.UTFMax]byte
+	off := 1
+	for _, r := range word {
+		n := utf8.EncodeRune(buf[:], r)
+		for _, b := range buf[:n] {
+			off = stepByte(ba, off, b)
+		}
+		if off == 0 {
+			return -1
+		}
+	}
+
+	return int(ba[off*nodeSize+nodeSize-1]) - 1
+}
+
+// ReaderKeywordIndex works like KeywordIndex except that the word is read from
+// r. It consumes exactly limit bytes from r, or fewer if r returns io.EOF
+// first. Bytes are consumed even after it has become clear that the word is
+// not a keyword, so that r is always left positioned at the end of the word.
+// An error other than io.EOF is returned together with an index of -1.
+func ReaderKeywordIndex[I constraints.Unsigned](trie GenericTrie[I], r io.ByteReader, limit int) (int, error) {
+	ba := trie.backingSlice
+
+	off := 1
+	for i := 0; i < limit; i++ {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return -1, err
+		}
+		// Once off is zero, the lookup loops round the nowhere node.
+		off = stepByte(ba, off, b)
+	}
+
+	return int(ba[off*nodeSize+nodeSize-1]) - 1, nil
+}
+
+// SeqKeywordIndex works like KeywordIndex except that the word is given as a
+// sequence of bytes. Iteration stops as soon as it is clear that the word is
+// not a keyword.
+func SeqKeywordIndex[I constraints.Unsigned](trie GenericTrie[I], word iter.Seq[byte]) int {
+	// A yield function passed to an arbitrary sequence escapes to the heap, as
+	// would any variables that it captures. To avoid allocating on each call,
+	// the lookup state and its yield function are therefore reused via a pool.
+	// The pool is shared by all instantiations, so a state for a different I
+	// is dropped rather than reused.
+	st, _ := seqStatePool.Get().(*seqState[I])
+	if st == nil {
+		st = &seqState[I]{}
+		st.yield = st.step
+	}
+
+	st.ba = trie.backingSlice
+	st.off = 1
+	word(st.yield)
+	i := int(st.ba[st.off*nodeSize+nodeSize-1]) - 1
+
+	st.ba = nil
+	seqStatePool.Put(st)
+	return i
+}
+
+// seqStatePool holds *seqState[I] values for reuse by SeqKeywordIndex.
+var seqStatePool sync.Pool
+
+type seqState[I constraints.Unsigned] struct {
+	ba    []I
+	off   int
+	yield func(byte) bool
+}
+
+func (st *seqState[I]) step(b byte) bool {
+	st.off = stepByte(st.ba, st.off, b)
+	return st.off != 0
+}
+
+// stepByte returns the offset of the node reached from node off by following
+// the high and then the low nibble of b.
+func stepByte[I constraints.Unsigned](ba []I, off int, b byte) int {
+	// As in KeywordIndex, there's no need to check for a zero offset after the
+	// high nibble.
+	off = int(ba[off*nodeSize+int(b>>4)])
+	return int(ba[off*nodeSize+int(b&0xF)])
+}
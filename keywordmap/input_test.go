@@ -0,0 +1,104 @@
+package keywordmap
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRuneKeywordIndex(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"for", "für", "日本", "�"})
+
+	for w, i := range map[string]int{"for": 0, "für": 1, "日本": 2, "�": 3, "fü": -1, "日": -1, "": -1, "forx": -1} {
+		if RuneKeywordIndex(trie, []rune(w)) != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, w, RuneKeywordIndex(trie, []rune(w)))
+		}
+	}
+	if RuneKeywordIndex(trie, []rune{-1}) != 3 {
+		t.Errorf("Expecting invalid rune to be encoded as U+FFFD")
+	}
+}
+
+func TestReaderKeywordIndex(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"for", "if"})
+
+	r := strings.NewReader("forxifif")
+	for _, c := range []struct {
+		limit, index int
+	}{{3, 0}, {1, -1}, {2, 1}, {1, -1}, {5, -1}} {
+		i, err := ReaderKeywordIndex(trie, r, c.limit)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if i != c.index {
+			t.Errorf("Expecting index %v, got %v", c.index, i)
+		}
+	}
+
+	r = strings.NewReader("forever")
+	if i, _ := ReaderKeywordIndex(trie, r, 3); i != 0 {
+		t.Errorf("Expecting index 0, got %v", i)
+	}
+	if rest, _ := io.ReadAll(r); string(rest) != "ever" {
+		t.Errorf("Expecting reader to be positioned after word, got remainder '%v'", rest)
+	}
+
+	r = strings.NewReader("xyzfor")
+	if i, _ := ReaderKeywordIndex(trie, r, 3); i != -1 {
+		t.Errorf("Expecting index -1, got %v", i)
+	}
+	if i, _ := ReaderKeywordIndex(trie, bufio.NewReader(r), 3); i != 0 {
+		t.Errorf("Expecting index 0, got %v", i)
+	}
+}
+
+type failingReader struct{}
+
+var errTest = errors.New("test error")
+
+func (failingReader) ReadByte() (byte, error) { return 0, errTest }
+
+func TestReaderKeywordIndexError(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"for"})
+	if i, err := ReaderKeywordIndex(trie, failingReader{}, 3); i != -1 || err != errTest {
+		t.Errorf("Expecting (-1, errTest), got (%v, %v)", i, err)
+	}
+}
+
+func TestSeqKeywordIndex(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+	for _, w := range append(td.ToTest, "") {
+		if SeqKeywordIndex(trie, slices.Values([]byte(w))) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, SeqKeywordIndex(trie, slices.Values([]byte(w))))
+		}
+	}
+}
+
+func TestInputLookupsDoNotAllocate(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"for", "für"})
+	runes := []rune("für")
+	bytes := []byte("für")
+	seq := slices.Values(bytes)
+	r := strings.NewReader("für")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if RuneKeywordIndex(trie, runes) != 1 {
+			panic("unexpected result")
+		}
+		if SeqKeywordIndex(trie, seq) != 1 {
+			panic("unexpected result")
+		}
+		r.Reset("für")
+		if i, _ := ReaderKeywordIndex(trie, r, len(bytes)); i != 1 {
+			panic("unexpected result")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expecting no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,108 @@
+package keywordmap
+
+// Interner maps strings to dense integer IDs using the same trie layout as
+// GenericTrie. IDs are assigned in order starting from zero. The backing array
+// starts out with 16-bit elements and is widened to 32-bit elements once it
+// becomes too big, so an Interner can hold arbitrarily large sets of strings.
+// An Interner should be constructed only via MakeInterner.
+type Interner struct {
+	narrow  GenericTrie[uint16]
+	wide    GenericTrie[uint32]
+	isWide  bool
+	strings []string
+	// The trie cannot represent the empty string, so its ID is stored
+	// separately (-1 if it has not been interned).
+	emptyID int
+}
+
+// MakeInterner returns a new empty Interner.
+func MakeInterner() *Interner {
+	return &Interner{narrow: MakeEmptyTrie[uint16](), emptyID: -1}
+}
+
+// Intern returns the ID of s, assigning it the next available ID if s has not
+// been interned before.
+func (in *Interner) Intern(s string) int {
+	if id := in.Lookup(s); id != -1 {
+		return id
+	}
+	return in.add(s)
+}
+
+// InternBytes works like Intern except that it takes a byte slice. The byte
+// slice is copied only if it has not been interned before.
+func (in *Interner) InternBytes(b []byte) int {
+	if id := in.LookupBytes(b); id != -1 {
+		return id
+	}
+	return in.add(string(b))
+}
+
+// Lookup returns the ID of s, or -1 if s has not been interned.
+func (in *Interner) Lookup(s string) int {
+	if s == "" {
+		return in.emptyID
+	}
+	if in.isWide {
+		return KeywordIndex(in.wide, s)
+	}
+	return KeywordIndex(in.narrow, s)
+}
+
+// LookupBytes works like Lookup except that it takes a byte slice.
+func (in *Interner) LookupBytes(b []byte) int {
+	if len(b) == 0 {
+		return in.emptyID
+	}
+	if in.isWide {
+		return KeywordIndex(in.wide, b)
+	}
+	return KeywordIndex(in.narrow, b)
+}
+
+// String returns the string with the given ID. It panics if no string has
+// been assigned the ID.
+func (in *Interner) String(id int) string {
+	return in.strings[id]
+}
+
+// Len returns the number of strings that have been interned.
+func (in *Interner) Len() int {
+	return len(in.strings)
+}
+
+func (in *Interner) add(s string) int {
+	id := len(in.strings)
+
+	switch {
+	case s == "":
+		in.emptyID = id
+	case in.isWide:
+		in.addWide(s, id)
+	case !AddToTrie(&in.narrow, s, id):
+		// A failed AddToTrie may leave some nodes for a prefix of s in the trie,
+		// but these are valid nodes with no keyword, so it's fine to copy them
+		// over and then add s to the widened trie.
+		in.widen()
+		in.addWide(s, id)
+	}
+
+	in.strings = append(in.strings, s)
+	return id
+}
+
+func (in *Interner) addWide(s string, id int) {
+	if !AddToTrie(&in.wide, s, id) {
+		panic("Interner capacity exceeded")
+	}
+}
+
+func (in *Interner) widen() {
+	ws := make([]uint32, len(in.narrow.backingSlice), len(in.narrow.backingSlice)*2)
+	for i, v := range in.narrow.backingSlice {
+		ws[i] = uint32(v)
+	}
+	in.wide = GenericTrie[uint32]{ws}
+	in.narrow = GenericTrie[uint16]{}
+	in.isWide = true
+}
@@ -0,0 +1,114 @@
+package keywordmap
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestInterner(t *testing.T) {
+	in := MakeInterner()
+
+	for i, s := range []string{"foo", "bar", "", "fo", "foobar"} {
+		if id := in.Intern(s); id != i {
+			t.Errorf("Expecting '%v' to be assigned ID %v, got %v", s, i, id)
+		}
+	}
+	if id := in.Intern("bar"); id != 1 {
+		t.Errorf("Expecting 'bar' to keep ID 1, got %v", id)
+	}
+	if id := in.InternBytes([]byte("fo")); id != 3 {
+		t.Errorf("Expecting 'fo' to keep ID 3, got %v", id)
+	}
+	if id := in.Lookup("f"); id != -1 {
+		t.Errorf("Did not expect to find 'f', got %v", id)
+	}
+	if in.Len() != 5 {
+		t.Errorf("Expecting 5 interned strings, got %v", in.Len())
+	}
+	if in.String(4) != "foobar" || in.String(2) != "" {
+		t.Errorf("Unexpected strings for IDs 4 and 2: '%v', '%v'", in.String(4), in.String(2))
+	}
+}
+
+func TestInternerWidens(t *testing.T) {
+	in := MakeInterner()
+
+	const n = 20000
+	for i := 0; i < n; i++ {
+		if id := in.Intern(fmt.Sprintf("id%v", i)); id != i {
+			t.Fatalf("Expecting ID %v, got %v", i, id)
+		}
+	}
+	if !in.isWide {
+		t.Errorf("Expecting interner to have widened its backing array")
+	}
+	for i := 0; i < n; i++ {
+		s := fmt.Sprintf("id%v", i)
+		if id := in.Lookup(s); id != i {
+			t.Errorf("Expecting ID %v for '%v', got %v", i, s, id)
+		}
+		if in.String(i) != s {
+			t.Errorf("Expecting string '%v' for ID %v, got '%v'", s, i, in.String(i))
+		}
+	}
+	if id := in.Lookup("id"); id != -1 {
+		t.Errorf("Did not expect to find 'id', got %v", id)
+	}
+}
+
+func getIdentifiers(n int) []string {
+	r := rand.New(rand.NewSource(randSeed))
+	letters := "abcdefghijklmnopqrstuvwxyz_"
+	ids := make([]string, n)
+	for i := range ids {
+		b := make([]byte, r.Intn(12)+1)
+		for j := range b {
+			b[j] = letters[r.Intn(len(letters))]
+		}
+		ids[i] = string(b)
+	}
+	return ids
+}
+
+func BenchmarkInterner(b *testing.B) {
+	ids := getIdentifiers(5000)
+	in := MakeInterner()
+	for _, s := range ids {
+		in.Intern(s)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, s := range ids {
+			in.Intern(s)
+		}
+	}
+}
+
+func BenchmarkInternerMap(b *testing.B) {
+	ids := getIdentifiers(5000)
+	m := make(map[string]int32)
+	var strs []string
+	intern := func(s string) int32 {
+		if id, ok := m[s]; ok {
+			return id
+		}
+		id := int32(len(strs))
+		m[s] = id
+		strs = append(strs, s)
+		return id
+	}
+	for _, s := range ids {
+		intern(s)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, s := range ids {
+			intern(s)
+		}
+	}
+}
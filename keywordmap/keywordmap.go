@@ -9,8 +9,10 @@
 // Internally, keywordmap constructs a compact trie backed by an array of
 // unsigned integers. The default (and recommended) Trie type uses 16-bit
 // integers. This is sufficient for realistically sized sets of keywords. You
-// may use GenericTrie[uint32] for larger tries. However, this package is not
-// optimized for dealing with large sets of keywords.
+// may use GenericTrie[uint32] for larger tries. However, tries are not
+// optimized for dealing with large sets of keywords. For sets with thousands of
+// members, GenericTernaryTree uses far less memory than GenericTrie (although
+// lookups using map[string]int are faster than both).
 package keywordmap
 
 import "golang.org/x/exp/constraints"
@@ -243,6 +243,39 @@ func BenchmarkTrie(b *testing.B) {
 	}
 }
 
+func BenchmarkPackedTrie(b *testing.B) {
+	trie, ok := MakePackedTrie([]string{"debug", "with", "and", "for", "case", "to", "form"})
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if PackedKeywordIndex(trie, "cape") != -1 {
+			panic("Internal error [25] in benchmark")
+		}
+		if PackedKeywordIndex(trie, "dooby") != -1 {
+			panic("Internal error [26] in benchmark")
+		}
+		if PackedKeywordIndex(trie, "fudge") != -1 {
+			panic("Internal error [27] in benchmark")
+		}
+		if PackedKeywordIndex(trie, "case") == -1 {
+			panic("Internal error [28] in benchmark")
+		}
+		if PackedKeywordIndex(trie, "debug") == -1 {
+			panic("Internal error [29] in benchmark")
+		}
+		if PackedKeywordIndex(trie, "for") == -1 {
+			panic("Internal error [30] in benchmark")
+		}
+		if PackedKeywordIndex(trie, "form") == -1 {
+			panic("Internal error [31] in benchmark")
+		}
+	}
+}
+
 func BenchmarkHash(b *testing.B) {
 	keywords := map[string]int{
 		"debug": 0,
@@ -316,6 +349,33 @@ func BenchmarkRandomTrie(b *testing.B) {
 	}
 }
 
+func BenchmarkRandomPackedTrie(b *testing.B) {
+	source := rand.NewSource(randSeed)
+	r := rand.New(source)
+
+	td := getRandomTestData(r)
+	trie, ok := MakePackedTrie(td.Keywords)
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if td.InTrie[j] {
+				if PackedKeywordIndex(trie, w) == -1 {
+					panic("Internal error [32] in benchmark")
+				}
+			} else {
+				if PackedKeywordIndex(trie, w) != -1 {
+					panic("Internal error [33] in benchmark")
+				}
+			}
+		}
+	}
+}
+
 func BenchmarkRandomHash(b *testing.B) {
 	source := rand.NewSource(randSeed)
 	r := rand.New(source)
@@ -0,0 +1,413 @@
+package keywordmap
+
+import (
+	"math/bits"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Matcher is implemented by each of the keyword sets in this package, and by
+// MapMatcher. It allows code such as a lexer to be written independently of
+// the representation of its keywords. The methods are not generic, so each
+// lookup comes in a string and a byte slice flavor. Calling a lookup through a
+// Matcher is somewhat slower than calling the corresponding function (e.g.
+// KeywordIndex) directly.
+type Matcher interface {
+	// Index returns the index of word in the list of keywords that the
+	// matcher was constructed from, or -1 if it is not present.
+	Index(word string) int
+	// IndexBytes is like Index, except that word is a byte slice.
+	IndexBytes(word []byte) int
+	// LongestPrefix finds the longest keyword that input begins with. It
+	// returns the index of the keyword and the position in input where the
+	// keyword ends. If input does not begin with any keyword, it returns -1
+	// and 0.
+	LongestPrefix(input string) (index, end int)
+	// LongestPrefixBytes is like LongestPrefix, except that input is a byte
+	// slice.
+	LongestPrefixBytes(input []byte) (index, end int)
+}
+
+// LongestPrefix finds the longest keyword that input begins with. It returns
+// the index of the keyword and the position in input where the keyword ends. If
+// input does not begin with any keyword, it returns -1 and 0.
+func LongestPrefix[T ByteIndexable, I constraints.Unsigned](trie GenericTrie[I], input T) (index, end int) {
+	ba := trie.backingSlice
+
+	index = -1
+	off := 1
+	for i := 0; i < len(input); i++ {
+		off = stepByte(ba, off, input[i])
+		if off == 0 {
+			break
+		}
+		if ki := int(ba[off*nodeSize+nodeSize-1]) - 1; ki != -1 {
+			index, end = ki, i+1
+		}
+	}
+
+	return
+}
+
+func (trie GenericTrie[I]) Index(word string) int      { return KeywordIndex(trie, word) }
+func (trie GenericTrie[I]) IndexBytes(word []byte) int { return KeywordIndex(trie, word) }
+func (trie GenericTrie[I]) LongestPrefix(input string) (int, int) {
+	return LongestPrefix(trie, input)
+}
+func (trie GenericTrie[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return LongestPrefix(trie, input)
+}
+
+func (trie GenericFilteredTrie[I]) Index(word string) int {
+	return FilteredKeywordIndex(trie, word)
+}
+func (trie GenericFilteredTrie[I]) IndexBytes(word []byte) int {
+	return FilteredKeywordIndex(trie, word)
+}
+func (trie GenericFilteredTrie[I]) LongestPrefix(input string) (int, int) {
+	return filteredLongestPrefix(trie, input)
+}
+func (trie GenericFilteredTrie[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return filteredLongestPrefix(trie, input)
+}
+
+func filteredLongestPrefix[T ByteIndexable, I constraints.Unsigned](trie GenericFilteredTrie[I], input T) (index, end int) {
+	// Only the first byte filter applies to prefixes.
+	if len(input) == 0 || len(input) < trie.minLen || trie.firstBytes[input[0]>>6]&(1<<(input[0]&63)) == 0 {
+		return -1, 0
+	}
+	return LongestPrefix(trie.trie, input)
+}
+
+func (trie GenericSparseTrie[I]) Index(word string) int      { return SparseKeywordIndex(trie, word) }
+func (trie GenericSparseTrie[I]) IndexBytes(word []byte) int { return SparseKeywordIndex(trie, word) }
+func (trie GenericSparseTrie[I]) LongestPrefix(input string) (int, int) {
+	return sparseLongestPrefix(trie, input)
+}
+func (trie GenericSparseTrie[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return sparseLongestPrefix(trie, input)
+}
+
+func sparseLongestPrefix[T ByteIndexable, I SparseElem](trie GenericSparseTrie[I], input T) (index, end int) {
+	ba := trie.backingSlice
+
+	index = -1
+	off := 0
+	for i := 0; i < len(input); i++ {
+		b := int(input[i])
+
+		bitmap := uint16(ba[off])
+		bit := uint16(1) << (b >> 4)
+		if bitmap&bit == 0 {
+			return
+		}
+		off = int(ba[off+2+bits.OnesCount16(bitmap&(bit-1))])
+
+		bitmap = uint16(ba[off])
+		bit = uint16(1) << (b & 0xF)
+		if bitmap&bit == 0 {
+			return
+		}
+		off = int(ba[off+2+bits.OnesCount16(bitmap&(bit-1))])
+
+		if ki := int(ba[off+1]) - 1; ki != -1 {
+			index, end = ki, i+1
+		}
+	}
+
+	return
+}
+
+func (trie GenericPackedTrie[I]) Index(word string) int      { return PackedKeywordIndex(trie, word) }
+func (trie GenericPackedTrie[I]) IndexBytes(word []byte) int { return PackedKeywordIndex(trie, word) }
+func (trie GenericPackedTrie[I]) LongestPrefix(input string) (int, int) {
+	return packedLongestPrefix(&trie, input)
+}
+func (trie GenericPackedTrie[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return packedLongestPrefix(&trie, input)
+}
+
+func packedLongestPrefix[T ByteIndexable, I constraints.Unsigned](trie *GenericPackedTrie[I], input T) (index, end int) {
+	// Keywords in the long trie are longer than any packed keyword, so a
+	// match there takes priority.
+	if len(input) > maxPackedLen {
+		if index, end = LongestPrefix(trie.long, input); index != -1 {
+			return
+		}
+	}
+
+	n := min(len(input), maxPackedLen)
+	var p uint64
+	for i := 0; i < n; i++ {
+		p |= uint64(input[i]) << (56 - 8*i)
+	}
+	for ; n > 0; n-- {
+		mask := ^uint64(0) << (64 - 8*n)
+		if index = searchPacked(trie, p&mask, n); index != -1 {
+			return index, n
+		}
+	}
+	return -1, 0
+}
+
+func (trie *GenericByteClassTrie[I]) Index(word string) int {
+	return ByteClassKeywordIndex(trie, word)
+}
+func (trie *GenericByteClassTrie[I]) IndexBytes(word []byte) int {
+	return ByteClassKeywordIndex(trie, word)
+}
+func (trie *GenericByteClassTrie[I]) LongestPrefix(input string) (int, int) {
+	return byteClassLongestPrefix(trie, input)
+}
+func (trie *GenericByteClassTrie[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return byteClassLongestPrefix(trie, input)
+}
+
+func byteClassLongestPrefix[T ByteIndexable, I constraints.Unsigned](trie *GenericByteClassTrie[I], input T) (index, end int) {
+	ba := trie.backingSlice
+	ns := trie.nodeSize
+
+	index = -1
+	off := 1
+	for i := 0; i < len(input); i++ {
+		c := int(trie.classes[input[i]])
+		if c == 0 {
+			break
+		}
+		off = int(ba[off*ns+c])
+		if off == 0 {
+			break
+		}
+		if ki := int(ba[off*ns]) - 1; ki != -1 {
+			index, end = ki, i+1
+		}
+	}
+
+	return
+}
+
+func (view GenericTrieView[I]) Index(word string) int      { return ViewKeywordIndex(view, word) }
+func (view GenericTrieView[I]) IndexBytes(word []byte) int { return ViewKeywordIndex(view, word) }
+func (view GenericTrieView[I]) LongestPrefix(input string) (int, int) {
+	return viewLongestPrefix(view, input)
+}
+func (view GenericTrieView[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return viewLongestPrefix(view, input)
+}
+
+func viewLongestPrefix[T ByteIndexable, I constraints.Unsigned](view GenericTrieView[I], input T) (index, end int) {
+	index = -1
+	off := 1
+	for i := 0; i < len(input); i++ {
+		b := int(input[i])
+		off = view.at(off*nodeSize + (b >> 4))
+		off = view.at(off*nodeSize + (b & 0xF))
+		if off == 0 {
+			break
+		}
+		if ki := view.at(off*nodeSize+nodeSize-1) - 1; ki != -1 {
+			index, end = ki, i+1
+		}
+	}
+
+	return
+}
+
+func (tree GenericTernaryTree[I]) Index(word string) int {
+	return TernaryKeywordIndex(tree, word)
+}
+func (tree GenericTernaryTree[I]) IndexBytes(word []byte) int {
+	return TernaryKeywordIndex(tree, word)
+}
+func (tree GenericTernaryTree[I]) LongestPrefix(input string) (int, int) {
+	return ternaryLongestPrefix(tree, input)
+}
+func (tree GenericTernaryTree[I]) LongestPrefixBytes(input []byte) (int, int) {
+	return ternaryLongestPrefix(tree, input)
+}
+
+func ternaryLongestPrefix[T ByteIndexable, I constraints.Unsigned](tree GenericTernaryTree[I], input T) (index, end int) {
+	splitBytes := tree.splitBytes
+	ba := tree.backingSlice
+
+	index = -1
+	off := 1
+	if len(splitBytes) == 1 {
+		return
+	}
+	for i := 0; i < len(input); {
+		b := input[i]
+		c := splitBytes[off]
+		switch {
+		case b < c:
+			off = int(ba[off*tstNodeSize])
+		case b > c:
+			off = int(ba[off*tstNodeSize+2])
+		default:
+			i++
+			if ki := int(ba[off*tstNodeSize+3]) - 1; ki != -1 {
+				index, end = ki, i
+			}
+			off = int(ba[off*tstNodeSize+1])
+		}
+		if off == 0 {
+			break
+		}
+	}
+
+	return
+}
+
+// MapMatcher is a Matcher backed by a map[string]int. It should be constructed
+// only via MakeMapMatcher.
+type MapMatcher struct {
+	indices        map[string]int
+	minLen, maxLen int
+}
+
+// MakeMapMatcher constructs a MapMatcher from a set of keywords. As with
+// MakeTrie, a repeated keyword takes the index of its last occurrence and the
+// empty keyword is ignored. Unlike MakeTrie, it cannot fail.
+func MakeMapMatcher[T ByteIndexable](keywords []T) MapMatcher {
+	m := MapMatcher{indices: make(map[string]int, len(keywords)), minLen: -1}
+	for i, k := range keywords {
+		if len(k) == 0 {
+			continue
+		}
+		m.indices[string(k)] = i
+		if m.minLen == -1 || len(k) < m.minLen {
+			m.minLen = len(k)
+		}
+		m.maxLen = max(m.maxLen, len(k))
+	}
+	return m
+}
+
+func (m MapMatcher) Index(word string) int {
+	if i, ok := m.indices[word]; ok {
+		return i
+	}
+	return -1
+}
+
+func (m MapMatcher) IndexBytes(word []byte) int {
+	if i, ok := m.indices[string(word)]; ok {
+		return i
+	}
+	return -1
+}
+
+func (m MapMatcher) LongestPrefix(input string) (int, int) {
+	for n := min(len(input), m.maxLen); n >= m.minLen && n > 0; n-- {
+		if i, ok := m.indices[input[:n]]; ok {
+			return i, n
+		}
+	}
+	return -1, 0
+}
+
+func (m MapMatcher) LongestPrefixBytes(input []byte) (int, int) {
+	for n := min(len(input), m.maxLen); n >= m.minLen && n > 0; n-- {
+		if i, ok := m.indices[string(input[:n])]; ok {
+			return i, n
+		}
+	}
+	return -1, 0
+}
+
+// MakeFastestMatcher constructs a Matcher for each of the backends in this
+// package that can accommodate keywords, times Index lookups of each word in
+// sample, and returns the fastest. If sample is empty, the keywords themselves
+// are used. The timings take on the order of a few milliseconds, and the
+// choice may vary from run to run when several backends have similar
+// performance. If no trie-based backend can be constructed, a MapMatcher is
+// returned.
+func MakeFastestMatcher[T ByteIndexable](keywords []T, sample []T) Matcher {
+	candidates := makeMatcherCandidates(keywords)
+
+	words := make([]string, 0, max(len(sample), len(keywords)))
+	for _, w := range sample {
+		words = append(words, string(w))
+	}
+	if len(words) == 0 {
+		for _, w := range keywords {
+			words = append(words, string(w))
+		}
+	}
+	if len(words) == 0 {
+		return candidates[0]
+	}
+
+	// Each timing covers at least this many lookups, so that the overhead of
+	// reading the clock is negligible.
+	const minLookups = 10000
+	const rounds = 5
+	passes := (minLookups + len(words) - 1) / len(words)
+
+	best := make([]time.Duration, len(candidates))
+	sink := 0
+	// Candidates are timed in alternation, so that a transient slowdown does
+	// not penalize a single candidate.
+	for r := 0; r < rounds; r++ {
+		for ci, c := range candidates {
+			start := time.Now()
+			for p := 0; p < passes; p++ {
+				for _, w := range words {
+					sink += c.Index(w)
+				}
+			}
+			d := time.Since(start)
+			if r == 0 || d < best[ci] {
+				best[ci] = d
+			}
+		}
+	}
+	matcherSink = sink
+
+	winner := 0
+	for ci := range candidates {
+		if best[ci] < best[winner] {
+			winner = ci
+		}
+	}
+	return candidates[winner]
+}
+
+// matcherSink prevents the lookups timed by MakeFastestMatcher from being
+// optimized away.
+var matcherSink int
+
+// makeMatcherCandidates returns a Matcher for each backend that can
+// accommodate keywords, starting with a MapMatcher. Each trie-based backend is
+// tried with uint16 and then uint32 elements. Ternary search trees are always
+// constructed with uint32 elements.
+func makeMatcherCandidates[T ByteIndexable](keywords []T) []Matcher {
+	candidates := []Matcher{MakeMapMatcher(keywords)}
+
+	if trie, ok := MakeGenericTrie[uint16](keywords); ok {
+		candidates = append(candidates, trie, FilterTrie(trie))
+	} else if trie, ok := MakeGenericTrie[uint32](keywords); ok {
+		candidates = append(candidates, trie, FilterTrie(trie))
+	}
+	if st, ok := MakeGenericSparseTrie[uint16](keywords); ok {
+		candidates = append(candidates, st)
+	} else if st, ok := MakeGenericSparseTrie[uint32](keywords); ok {
+		candidates = append(candidates, st)
+	}
+	if pt, ok := MakeGenericPackedTrie[uint16](keywords); ok {
+		candidates = append(candidates, pt)
+	} else if pt, ok := MakeGenericPackedTrie[uint32](keywords); ok {
+		candidates = append(candidates, pt)
+	}
+	if tree, ok := MakeGenericTernaryTree[uint32](keywords); ok {
+		candidates = append(candidates, tree)
+	}
+	if bt, ok := MakeGenericByteClassTrie[uint16](keywords, nil); ok {
+		candidates = append(candidates, &bt)
+	} else if bt, ok := MakeGenericByteClassTrie[uint32](keywords, nil); ok {
+		candidates = append(candidates, &bt)
+	}
+
+	return candidates
+}
@@ -0,0 +1,141 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func referenceLongestPrefix(keywords []string, input string) (index, end int) {
+	index = -1
+	for i, k := range keywords {
+		if k != "" && len(k) >= end && strings.HasPrefix(input, k) {
+			index, end = i, len(k)
+		}
+	}
+	return
+}
+
+func getMatchers(t *testing.T, keywords []string) map[string]Matcher {
+	trie := mustMakeTrie(t, keywords)
+	st, ok := MakeSparseTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting sparse trie to be constructed successfully")
+	}
+	pt, ok := MakePackedTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting packed trie to be constructed successfully")
+	}
+	bt, ok := MakeByteClassTrie(keywords, nil)
+	if !ok {
+		t.Fatalf("Expecting byte class trie to be constructed successfully")
+	}
+	tree, ok := MakeTernaryTree(keywords)
+	if !ok {
+		t.Fatalf("Expecting ternary tree to be constructed successfully")
+	}
+	view, err := OpenTrieView[uint16](EncodeTrie(trie))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Matcher{
+		"trie":       trie,
+		"filtered":   FilterTrie(trie),
+		"sparse":     st,
+		"packed":     pt,
+		"byte class": &bt,
+		"view":       view,
+		"ternary":    tree,
+		"map":        MakeMapMatcher(keywords),
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	keywords := append(td.Keywords, "abcdefghij", "abcdefghijkl", "abcdefg")
+
+	inputs := append([]string{"", "abcdefghijk", "abcdefghijklm", "abcdefgh"}, td.ToTest...)
+	for _, w := range td.ToTest {
+		inputs = append(inputs, w+"xyz", w+w)
+	}
+
+	for name, m := range getMatchers(t, keywords) {
+		for _, w := range inputs {
+			expected := -1
+			for i, k := range keywords {
+				if k == w {
+					expected = i
+				}
+			}
+			if m.Index(w) != expected || m.IndexBytes([]byte(w)) != expected {
+				t.Errorf("%v: expecting index %v for %q, got %v and %v", name, expected, w, m.Index(w), m.IndexBytes([]byte(w)))
+			}
+
+			ei, ee := referenceLongestPrefix(keywords, w)
+			if i, e := m.LongestPrefix(w); i != ei || e != ee {
+				t.Errorf("%v: expecting longest prefix (%v, %v) of %q, got (%v, %v)", name, ei, ee, w, i, e)
+			}
+			if i, e := m.LongestPrefixBytes([]byte(w)); i != ei || e != ee {
+				t.Errorf("%v: expecting longest prefix (%v, %v) of %q as byte slice, got (%v, %v)", name, ei, ee, w, i, e)
+			}
+		}
+	}
+}
+
+func TestEmptyMatchers(t *testing.T) {
+	for name, m := range getMatchers(t, nil) {
+		for _, w := range []string{"", "a", "abcdefghijk"} {
+			if m.Index(w) != -1 {
+				t.Errorf("%v: did not expect to find %q", name, w)
+			}
+			if i, e := m.LongestPrefix(w); i != -1 || e != 0 {
+				t.Errorf("%v: expecting no prefix of %q, got (%v, %v)", name, w, i, e)
+			}
+		}
+	}
+}
+
+func TestMakeFastestMatcher(t *testing.T) {
+	ids := getIdentifierMix(goKeywords)
+	m := MakeFastestMatcher(goKeywords, ids)
+	t.Logf("Selected %T", m)
+	for i, k := range goKeywords {
+		if m.Index(k) != i {
+			t.Errorf("Expecting index %v for %v, got %v", i, k, m.Index(k))
+		}
+	}
+	if m.Index("foo") != -1 {
+		t.Errorf("Did not expect to find foo")
+	}
+
+	// Too many keywords for any trie with uint16 elements.
+	ids = getIdentifiers(20000)
+	m = MakeFastestMatcher(ids, nil)
+	if m.Index(ids[123]) != 123 {
+		t.Errorf("Expecting index 123, got %v", m.Index(ids[123]))
+	}
+
+	m = MakeFastestMatcher[string](nil, nil)
+	if m.Index("foo") != -1 {
+		t.Errorf("Did not expect to find foo")
+	}
+}
+
+func BenchmarkIdentifierMixMatcher(b *testing.B) {
+	ids := getIdentifierMix(goKeywords)
+	m := MakeFastestMatcher(goKeywords, ids)
+
+	b.ResetTimer()
+
+	n := 0
+	for i := 0; i < b.N; i++ {
+		for _, w := range ids {
+			if m.Index(w) != -1 {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		panic("Internal error [39] in benchmark")
+	}
+}
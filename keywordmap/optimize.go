@@ -0,0 +1,123 @@
+package keywordmap
+
+import (
+	"slices"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Layout specifies the order in which Optimize places trie nodes in the
+// backing array.
+type Layout int
+
+const (
+	LayoutBreadthFirst Layout = iota // nodes ordered by depth
+	LayoutDepthFirst                 // each node followed by its descendants
+)
+
+func (l Layout) String() string {
+	switch l {
+	case LayoutBreadthFirst:
+		return "LayoutBreadthFirst"
+	case LayoutDepthFirst:
+		return "LayoutDepthFirst"
+	default:
+		panic("Unrecognized Layout")
+	}
+}
+
+// Optimize returns a copy of trie with its nodes renumbered according to
+// layout. AddToTrie appends nodes in insertion order, so the nodes visited by a
+// single lookup may be scattered throughout the backing array. Renumbering
+// them improves locality. If sample is non-empty, it is treated as a
+// representative set of lookups: the children of each node are then ordered by
+// the number of times the sample visits them, so that the hottest paths end up
+// next to each other in memory. Otherwise children are ordered by nibble.
+//
+// The returned trie gives exactly the same results as trie for all lookups.
+// Adding further keywords to it with AddToTrie is permitted, but the new nodes
+// will not be laid out optimally.
+func Optimize[I constraints.Unsigned, T ByteIndexable](trie GenericTrie[I], layout Layout, sample []T) GenericTrie[I] {
+	var hits []int
+	if len(sample) > 0 {
+		hits = make([]int, len(trie.backingSlice)/nodeSize)
+		for _, w := range sample {
+			countHits(trie.backingSlice, w, 1, hits)
+		}
+	}
+
+	return optimizeWithHits(trie, layout, hits)
+}
+
+// optimizeWithHits implements Optimize. If hits is non-nil then hits[n] is the
+// number of times that node n was visited.
+func optimizeWithHits[I constraints.Unsigned](trie GenericTrie[I], layout Layout, hits []int) GenericTrie[I] {
+	ba := trie.backingSlice
+	nNodes := len(ba) / nodeSize
+
+	// order[i] is the old number of the node that gets new number i.
+	order := make([]int, 0, nNodes)
+	order = append(order, 0, 1)
+	children := make([]int, 0, 16)
+	appendChildren := func(dst []int, old int) []int {
+		children = children[:0]
+		for nib := 0; nib < 16; nib++ {
+			if c := int(ba[old*nodeSize+nib]); c != 0 {
+				children = append(children, c)
+			}
+		}
+		if hits != nil {
+			slices.SortStableFunc(children, func(a, b int) int { return hits[b] - hits[a] })
+		}
+		return append(dst, children...)
+	}
+
+	switch layout {
+	case LayoutBreadthFirst:
+		for i := 1; i < len(order); i++ {
+			order = appendChildren(order, order[i])
+		}
+	case LayoutDepthFirst:
+		stack := []int{1}
+		order = order[:1]
+		for len(stack) > 0 {
+			old := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			order = append(order, old)
+			// push in reverse so that the first child is visited first
+			start := len(stack)
+			stack = appendChildren(stack, old)
+			slices.Reverse(stack[start:])
+		}
+	default:
+		panic("Unrecognized Layout")
+	}
+
+	renumber := make([]I, nNodes)
+	for n, old := range order {
+		renumber[old] = I(n)
+	}
+
+	newBa := make([]I, len(order)*nodeSize)
+	for n, old := range order {
+		for nib := 0; nib < 16; nib++ {
+			newBa[n*nodeSize+nib] = renumber[ba[old*nodeSize+nib]]
+		}
+		newBa[n*nodeSize+nodeSize-1] = ba[old*nodeSize+nodeSize-1]
+	}
+
+	return GenericTrie[I]{newBa}
+}
+
+func countHits[I constraints.Unsigned, T ByteIndexable](ba []I, word T, weight int, hits []int) {
+	off := 1
+	hits[off] += weight
+	for i := 0; i < len(word)*2; i++ {
+		b := (int(word[i/2]) >> (4 * ((i % 2) ^ 1))) & 0xF
+		off = int(ba[off*nodeSize+b])
+		if off == 0 {
+			return
+		}
+		hits[off] += weight
+	}
+}
@@ -0,0 +1,75 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+
+	for _, layout := range []Layout{LayoutBreadthFirst, LayoutDepthFirst} {
+		for _, sample := range [][]string{nil, td.ToTest, td.Keywords[:5]} {
+			opt := Optimize(trie, layout, sample)
+			if len(opt.backingSlice) != len(trie.backingSlice) {
+				t.Errorf("%v: expecting backing slice of length %v, got %v", layout, len(trie.backingSlice), len(opt.backingSlice))
+			}
+			for _, w := range td.ToTest {
+				if KeywordIndex(opt, w) != KeywordIndex(trie, w) {
+					t.Errorf("%v: expecting index %v for '%v', got %v", layout, KeywordIndex(trie, w), w, KeywordIndex(opt, w))
+				}
+			}
+			for _, w := range []string{"", "a", "zzzzzzzzzzzz"} {
+				if KeywordIndex(opt, w) != KeywordIndex(trie, w) {
+					t.Errorf("%v: expecting index %v for '%v', got %v", layout, KeywordIndex(trie, w), w, KeywordIndex(opt, w))
+				}
+			}
+		}
+	}
+}
+
+func TestOptimizeDepthFirstPutsHotPathFirst(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"a", "z"})
+	opt := Optimize(trie, LayoutDepthFirst, []string{"z", "z", "a"})
+
+	// nodes for 'z' should directly follow the root
+	if opt.backingSlice[1*nodeSize+('z'>>4)] != 2 {
+		t.Errorf("Expecting first node after root to be on the path for 'z'")
+	}
+	if KeywordIndex(opt, "a") != 0 || KeywordIndex(opt, "z") != 1 {
+		t.Errorf("Unexpected lookup results for optimized trie")
+	}
+}
+
+func benchmarkOptimizedTrie(b *testing.B, layout Layout, useSample bool) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(b, td.Keywords)
+	var sample []string
+	if useSample {
+		sample = td.ToTest
+	}
+	trie = Optimize(trie, layout, sample)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (KeywordIndex(trie, w) != -1) != td.InTrie[j] {
+				panic("Internal error [19] in benchmark")
+			}
+		}
+	}
+}
+
+func BenchmarkRandomTrieBreadthFirst(b *testing.B) {
+	benchmarkOptimizedTrie(b, LayoutBreadthFirst, false)
+}
+
+func BenchmarkRandomTrieDepthFirst(b *testing.B) {
+	benchmarkOptimizedTrie(b, LayoutDepthFirst, false)
+}
+
+func BenchmarkRandomTrieDepthFirstWithSample(b *testing.B) {
+	benchmarkOptimizedTrie(b, LayoutDepthFirst, true)
+}
@@ -0,0 +1,198 @@
+package keywordmap
+
+import (
+	"iter"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Floor returns the greatest keyword in trie that is less than or equal to s in
+// byte order, together with its index. If there is no such keyword, it returns
+// the empty string and -1.
+func Floor[T ByteIndexable, I constraints.Unsigned](trie GenericTrie[I], s T) (keyword string, index int) {
+	buf := make([]byte, 0, len(s)+8)
+	buf, index = floorHelper(trie.backingSlice, s, 1, buf)
+	return string(buf), index
+}
+
+// Ceiling returns the smallest keyword in trie that is greater than or equal to
+// s in byte order, together with its index. If there is no such keyword, it
+// returns the empty string and -1.
+func Ceiling[T ByteIndexable, I constraints.Unsigned](trie GenericTrie[I], s T) (keyword string, index int) {
+	buf := make([]byte, 0, len(s)+8)
+	buf, index = ceilingHelper(trie.backingSlice, s, 1, buf)
+	return string(buf), index
+}
+
+// Range returns a sequence of the keywords k in trie with lo <= k < hi in byte
+// order, together with their indices. The keywords are yielded in byte order.
+// Only the parts of the trie that lie between lo and hi are visited.
+func Range[T ByteIndexable, I constraints.Unsigned](trie GenericTrie[I], lo, hi T) iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		rangeHelper(trie.backingSlice, lo, hi, 1, true, true, make([]byte, 0, 16), yield)
+	}
+}
+
+// childAfter returns the first child of the node at off (in byte order) whose
+// byte is at least from, together with the byte. It returns 0 for the child
+// if there is no such child.
+func childAfter[I constraints.Unsigned](ba []I, off, from int) (byte, int) {
+	for b := from; b < 256; {
+		mid := int(ba[off*nodeSize+(b>>4)])
+		if mid == 0 {
+			b = (b>>4 + 1) << 4
+			continue
+		}
+		if child := int(ba[mid*nodeSize+(b&0xF)]); child != 0 {
+			return byte(b), child
+		}
+		b++
+	}
+	return 0, 0
+}
+
+// childBefore returns the last child of the node at off (in byte order) whose
+// byte is at most from, together with the byte. It returns 0 for the child if
+// there is no such child.
+func childBefore[I constraints.Unsigned](ba []I, off, from int) (byte, int) {
+	for b := from; b >= 0; {
+		mid := int(ba[off*nodeSize+(b>>4)])
+		if mid == 0 {
+			b = b>>4<<4 - 1
+			continue
+		}
+		if child := int(ba[mid*nodeSize+(b&0xF)]); child != 0 {
+			return byte(b), child
+		}
+		b--
+	}
+	return 0, 0
+}
+
+// floorHelper finds the greatest keyword <= s in the subtree at off, where
+// the prefix leading to off (in buf) is equal to s[:len(buf)].
+func floorHelper[T ByteIndexable, I constraints.Unsigned](ba []I, s T, off int, buf []byte) ([]byte, int) {
+	d := len(buf)
+	terminal := int(ba[off*nodeSize+nodeSize-1]) - 1
+	if d == len(s) {
+		// Every other keyword in the subtree extends s, so is greater than s.
+		return buf, terminal
+	}
+
+	if child := stepByte(ba, off, s[d]); child != 0 {
+		if r, ki := floorHelper(ba, s, child, append(buf, s[d])); ki != -1 {
+			return r, ki
+		}
+	}
+	for from := int(s[d]) - 1; from >= 0; {
+		b, child := childBefore(ba, off, from)
+		if child == 0 {
+			break
+		}
+		if r, ki := maxKeyword(ba, child, append(buf[:d], b)); ki != -1 {
+			return r, ki
+		}
+		from = int(b) - 1
+	}
+	return buf[:d], terminal
+}
+
+// ceilingHelper finds the smallest keyword >= s in the subtree at off, where
+// the prefix leading to off (in buf) is equal to s[:len(buf)].
+func ceilingHelper[T ByteIndexable, I constraints.Unsigned](ba []I, s T, off int, buf []byte) ([]byte, int) {
+	d := len(buf)
+	if d == len(s) {
+		return minKeyword(ba, off, buf)
+	}
+
+	if child := stepByte(ba, off, s[d]); child != 0 {
+		if r, ki := ceilingHelper(ba, s, child, append(buf, s[d])); ki != -1 {
+			return r, ki
+		}
+	}
+	for from := int(s[d]) + 1; from < 256; {
+		b, child := childAfter(ba, off, from)
+		if child == 0 {
+			break
+		}
+		if r, ki := minKeyword(ba, child, append(buf[:d], b)); ki != -1 {
+			return r, ki
+		}
+		from = int(b) + 1
+	}
+	return buf[:d], -1
+}
+
+// minKeyword finds the smallest keyword in the subtree at off. A subtree may
+// contain no keywords if an earlier call to AddToTrie failed.
+func minKeyword[I constraints.Unsigned](ba []I, off int, buf []byte) ([]byte, int) {
+	if ki := int(ba[off*nodeSize+nodeSize-1]) - 1; ki != -1 {
+		return buf, ki
+	}
+	d := len(buf)
+	for from := 0; from < 256; {
+		b, child := childAfter(ba, off, from)
+		if child == 0 {
+			break
+		}
+		if r, ki := minKeyword(ba, child, append(buf[:d], b)); ki != -1 {
+			return r, ki
+		}
+		from = int(b) + 1
+	}
+	return buf[:d], -1
+}
+
+// maxKeyword finds the greatest keyword in the subtree at off.
+func maxKeyword[I constraints.Unsigned](ba []I, off int, buf []byte) ([]byte, int) {
+	d := len(buf)
+	for from := 255; from >= 0; {
+		b, child := childBefore(ba, off, from)
+		if child == 0 {
+			break
+		}
+		if r, ki := maxKeyword(ba, child, append(buf[:d], b)); ki != -1 {
+			return r, ki
+		}
+		from = int(b) - 1
+	}
+	return buf[:d], int(ba[off*nodeSize+nodeSize-1]) - 1
+}
+
+// rangeHelper yields the keywords in [lo, hi) in the subtree at off, whose
+// prefix is buf. atLo (atHi) is true if buf is equal to lo[:len(buf)]
+// (hi[:len(buf)]). It returns false if the traversal should stop.
+func rangeHelper[T ByteIndexable, I constraints.Unsigned](ba []I, lo, hi T, off int, atLo, atHi bool, buf []byte, yield func(string, int) bool) bool {
+	d := len(buf)
+	if atHi && d == len(hi) {
+		// buf is equal to hi, and every keyword in the subtree is >= hi.
+		return true
+	}
+	if !atLo || d == len(lo) {
+		if ki := int(ba[off*nodeSize+nodeSize-1]) - 1; ki != -1 && !yield(string(buf), ki) {
+			return false
+		}
+	}
+
+	from, to := 0, 255
+	if atLo && d < len(lo) {
+		from = int(lo[d])
+	}
+	if atHi {
+		to = int(hi[d])
+	}
+	for from <= to {
+		b, child := childAfter(ba, off, from)
+		if child == 0 || int(b) > to {
+			break
+		}
+		childAtLo := atLo && d < len(lo) && b == lo[d]
+		childAtHi := atHi && b == hi[d]
+		if !rangeHelper(ba, lo, hi, child, childAtLo, childAtHi, append(buf, b), yield) {
+			return false
+		}
+		from = int(b) + 1
+	}
+
+	return true
+}
@@ -0,0 +1,125 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestFloorCeiling(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"b", "ba", "bat", "c", "cat", "\xff"})
+
+	cases := []struct {
+		s                     string
+		floor, ceiling        string
+		floorIndex, ceilIndex int
+	}{
+		{"", "", "b", -1, 0},
+		{"a", "", "b", -1, 0},
+		{"b", "b", "b", 0, 0},
+		{"b\x00", "b", "ba", 0, 1},
+		{"bar", "ba", "bat", 1, 2},
+		{"bat", "bat", "bat", 2, 2},
+		{"bats", "bat", "c", 2, 3},
+		{"bb", "bat", "c", 2, 3},
+		{"ca", "c", "cat", 3, 4},
+		{"d", "cat", "\xff", 4, 5},
+		{"\xff", "\xff", "\xff", 5, 5},
+		{"\xff\xff", "\xff", "", 5, -1},
+	}
+	for _, c := range cases {
+		if k, i := Floor(trie, c.s); k != c.floor || i != c.floorIndex {
+			t.Errorf("%q: expecting floor (%q, %v), got (%q, %v)", c.s, c.floor, c.floorIndex, k, i)
+		}
+		if k, i := Ceiling(trie, []byte(c.s)); k != c.ceiling || i != c.ceilIndex {
+			t.Errorf("%q: expecting ceiling (%q, %v), got (%q, %v)", c.s, c.ceiling, c.ceilIndex, k, i)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"b", "ba", "bat", "c", "cat", "\xff"})
+
+	cases := []struct {
+		lo, hi   string
+		expected []string
+	}{
+		{"", "\xff\xff", []string{"b", "ba", "bat", "c", "cat", "\xff"}},
+		{"", "", nil},
+		{"b", "c", []string{"b", "ba", "bat"}},
+		{"b\x00", "c\x00", []string{"ba", "bat", "c"}},
+		{"bat", "bat", nil},
+		{"c", "b", nil},
+		{"a", "bat", []string{"b", "ba"}},
+		{"bb", "z", []string{"c", "cat"}},
+	}
+	for _, c := range cases {
+		var keywords []string
+		for k, i := range Range(trie, c.lo, c.hi) {
+			if KeywordIndex(trie, k) != i {
+				t.Errorf("Expecting index %v for %q, got %v", KeywordIndex(trie, k), k, i)
+			}
+			keywords = append(keywords, k)
+		}
+		if !slices.Equal(keywords, c.expected) {
+			t.Errorf("[%q, %q): expecting %q, got %q", c.lo, c.hi, c.expected, keywords)
+		}
+	}
+}
+
+func TestRandomOrderedQueries(t *testing.T) {
+	r := rand.New(rand.NewSource(randSeed))
+	td := getRandomTestData(r)
+	trie := mustMakeTrie(t, td.Keywords)
+
+	sorted := slices.Clone(td.Keywords)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	randomString := func() string {
+		b := make([]byte, r.Intn(5))
+		for i := range b {
+			b[i] = byte('a' + r.Intn(27)) // includes '{', which sorts after 'z'
+		}
+		return string(b)
+	}
+
+	for i := 0; i < 2000; i++ {
+		s := randomString()
+
+		// The index of the first keyword >= s.
+		n, found := slices.BinarySearch(sorted, s)
+
+		expectedFloor := ""
+		if found {
+			expectedFloor = s
+		} else if n > 0 {
+			expectedFloor = sorted[n-1]
+		}
+		if k, ki := Floor(trie, s); k != expectedFloor || (k != "" && ki != KeywordIndex(trie, k)) {
+			t.Errorf("%q: expecting floor %q, got (%q, %v)", s, expectedFloor, k, ki)
+		}
+
+		expectedCeiling := ""
+		if n < len(sorted) {
+			expectedCeiling = sorted[n]
+		}
+		if k, ki := Ceiling(trie, s); k != expectedCeiling || (k != "" && ki != KeywordIndex(trie, k)) {
+			t.Errorf("%q: expecting ceiling %q, got (%q, %v)", s, expectedCeiling, k, ki)
+		}
+
+		hi := randomString()
+		m, _ := slices.BinarySearch(sorted, hi)
+		var expectedRange []string
+		if m > n {
+			expectedRange = sorted[n:m]
+		}
+		var keywords []string
+		for k := range Range(trie, s, hi) {
+			keywords = append(keywords, k)
+		}
+		if !slices.Equal(keywords, expectedRange) {
+			t.Errorf("[%q, %q): expecting %q, got %q", s, hi, expectedRange, keywords)
+		}
+	}
+}
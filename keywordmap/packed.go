@@ -0,0 +1,152 @@
+package keywordmap
+
+import (
+	"slices"
+
+	"golang.org/x/exp/constraints"
+)
+
+const maxPackedLen = 8
+
+const linearScanLen = 8
+
+// PackedTrie is the recommended instantiation of GenericPackedTrie.
+type PackedTrie = GenericPackedTrie[uint16]
+
+// GenericPackedTrie is an alternative to GenericTrie that is optimized for
+// short keywords. Keywords of up to 8 bytes are packed into uint64 words and
+// stored in sorted tables (one for each keyword length). A lookup of a short
+// input packs the input in the same way and then searches the table for the
+// input's length using a handful of word comparisons. Longer keywords are stored in an
+// ordinary GenericTrie. It should be constructed only via
+// MakeGenericPackedTrie or MakePackedTrie.
+type GenericPackedTrie[I constraints.Unsigned] struct {
+	// The packed keywords of length n are packed[offsets[n]:offsets[n+1]].
+	// indices[i] is the index of the keyword packed[i].
+	offsets [maxPackedLen + 2]int
+	packed  []uint64
+	indices []int
+	long    GenericTrie[I]
+}
+
+// MakePackedTrie calls MakeGenericPackedTrie with the I type parameter set to
+// uint16 (the recommended default).
+func MakePackedTrie[T ByteIndexable](keywords []T) (PackedTrie, bool) {
+	return MakeGenericPackedTrie[uint16](keywords)
+}
+
+// MakeGenericPackedTrie constructs a packed trie from a set of keywords. It
+// behaves like MakeGenericTrie. The I type parameter determines the type of the
+// trie used for keywords longer than 8 bytes.
+func MakeGenericPackedTrie[I constraints.Unsigned, T ByteIndexable](keywords []T) (GenericPackedTrie[I], bool) {
+	var pt GenericPackedTrie[I]
+	pt.long = MakeEmptyTrie[I]()
+
+	type entry struct {
+		len    int
+		packed uint64
+		index  int
+	}
+	var entries []entry
+	// As with AddToTrie, a repeated keyword takes the index of its last
+	// occurrence.
+	seen := make(map[entry]int)
+
+	for wi, k := range keywords {
+		if len(k) > maxPackedLen {
+			if !AddToTrie(&pt.long, k, wi) {
+				return makeEmptyPackedTrie[I](), false
+			}
+			continue
+		}
+		if len(k) == 0 {
+			// empty keywords are not supported by GenericTrie either
+			continue
+		}
+
+		e := entry{len(k), pack(k), 0}
+		if i, ok := seen[e]; ok {
+			entries[i].index = wi
+			continue
+		}
+		seen[e] = len(entries)
+		e.index = wi
+		entries = append(entries, e)
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		if a.len != b.len {
+			return a.len - b.len
+		}
+		if a.packed < b.packed {
+			return -1
+		}
+		if a.packed > b.packed {
+			return 1
+		}
+		return 0
+	})
+
+	pt.packed = make([]uint64, len(entries))
+	pt.indices = make([]int, len(entries))
+	for i, e := range entries {
+		pt.packed[i] = e.packed
+		pt.indices[i] = e.index
+		pt.offsets[e.len+1] = i + 1
+	}
+	for n := 1; n < len(pt.offsets); n++ {
+		if pt.offsets[n] < pt.offsets[n-1] {
+			pt.offsets[n] = pt.offsets[n-1]
+		}
+	}
+
+	return pt, true
+}
+
+func makeEmptyPackedTrie[I constraints.Unsigned]() GenericPackedTrie[I] {
+	return GenericPackedTrie[I]{long: MakeEmptyTrie[I]()}
+}
+
+// PackedKeywordIndex returns the index of word in the list of keywords passed
+// to MakePackedTrie/MakeGenericPackedTrie, or -1 if it is not present.
+func PackedKeywordIndex[T ByteIndexable, I constraints.Unsigned](trie GenericPackedTrie[I], word T) int {
+	if len(word) > maxPackedLen {
+		return KeywordIndex(trie.long, word)
+	}
+
+	return searchPacked(&trie, pack(word), len(word))
+}
+
+// searchPacked returns the index of the packed keyword p of length n, or -1 if
+// it is not present.
+func searchPacked[I constraints.Unsigned](trie *GenericPackedTrie[I], p uint64, n int) int {
+	lo, hi := trie.offsets[n], trie.offsets[n+1]
+
+	// Binary search narrows the range down to a few words, which are then
+	// compared linearly.
+	for hi-lo > linearScanLen {
+		mid := int(uint(lo+hi) >> 1)
+		if trie.packed[mid] < p {
+			lo = mid + 1
+		} else {
+			hi = mid + 1
+		}
+	}
+	for ; lo < hi; lo++ {
+		if trie.packed[lo] == p {
+			return trie.indices[lo]
+		}
+	}
+	return -1
+}
+
+// pack packs a word of at most 8 bytes into a uint64, with the first byte in
+// the most significant position. Unused low bytes are zero, so words of
+// different lengths may pack to the same value.
+func pack[T ByteIndexable](word T) uint64 {
+	var p uint64
+	for i := 0; i < len(word); i++ {
+		p |= uint64(word[i]) << (56 - 8*i)
+	}
+	return p
+}
@@ -0,0 +1,66 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPackedTrie(t *testing.T) {
+	keywords := []string{"debu", "with", "and", "for", "case", "to", "form", "fallthrough", "interface", "a\x00", "a"}
+	trie := mustMakeTrie(t, keywords)
+	pt, ok := MakePackedTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting packed trie to be constructed successfully")
+	}
+
+	inputs := append([]string{"", "\x00", "a\x00\x00", "fallthroug", "fallthroughs", "interfac", "tox", "t", "forma"}, keywords...)
+	inputs = append(inputs, getIdentifiers(1000)...)
+	for _, w := range inputs {
+		if PackedKeywordIndex(pt, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for %q, got %v", KeywordIndex(trie, w), w, PackedKeywordIndex(pt, w))
+		}
+		if PackedKeywordIndex(pt, []byte(w)) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for %q (as byte slice)", KeywordIndex(trie, w), w)
+		}
+	}
+}
+
+func TestPackedTrieRandom(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+	pt, ok := MakePackedTrie(td.Keywords)
+	if !ok {
+		t.Fatalf("Expecting packed trie to be constructed successfully")
+	}
+	for _, w := range td.ToTest {
+		if PackedKeywordIndex(pt, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, PackedKeywordIndex(pt, w))
+		}
+	}
+}
+
+func TestPackedTrieDuplicates(t *testing.T) {
+	keywords := []string{"for", "if", "for", "longkeyword", "longkeyword"}
+	trie := mustMakeTrie(t, keywords)
+	pt, ok := MakePackedTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting packed trie to be constructed successfully")
+	}
+	for _, w := range keywords {
+		if PackedKeywordIndex(pt, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, PackedKeywordIndex(pt, w))
+		}
+	}
+}
+
+func TestEmptyPackedTrie(t *testing.T) {
+	pt, ok := MakePackedTrie([]string{})
+	if !ok {
+		t.Fatalf("Expecting packed trie to be constructed successfully")
+	}
+	for _, w := range []string{"", "a", "abcdefghijk"} {
+		if PackedKeywordIndex(pt, w) != -1 {
+			t.Errorf("Did not expect to find '%v' in empty trie", w)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package presets
+
+// Indices of the C keywords in CKeywords and CTrie.
+const (
+	CAuto         = iota // auto
+	CBreak               // break
+	CCase                // case
+	CChar                // char
+	CConst               // const
+	CContinue            // continue
+	CDefault             // default
+	CDo                  // do
+	CDouble              // double
+	CElse                // else
+	CEnum                // enum
+	CExtern              // extern
+	CFloat               // float
+	CFor                 // for
+	CGoto                // goto
+	CIf                  // if
+	CInline              // inline
+	CInt                 // int
+	CLong                // long
+	CRegister            // register
+	CRestrict            // restrict
+	CReturn              // return
+	CShort               // short
+	CSigned              // signed
+	CSizeof              // sizeof
+	CStatic              // static
+	CStruct              // struct
+	CSwitch              // switch
+	CTypedef             // typedef
+	CUnion               // union
+	CUnsigned            // unsigned
+	CVoid                // void
+	CVolatile            // volatile
+	CWhile               // while
+	CAlignas             // _Alignas
+	CAlignof             // _Alignof
+	CAtomic              // _Atomic
+	CBool                // _Bool
+	CComplex             // _Complex
+	CGeneric             // _Generic
+	CImaginary           // _Imaginary
+	CNoreturn            // _Noreturn
+	CStaticAssert        // _Static_assert
+	CThreadLocal         // _Thread_local
+)
+
+// CKeywords lists the C keywords, as defined by the C17 standard.
+var CKeywords = []string{
+	"auto", "break", "case", "char", "const", "continue", "default", "do",
+	"double", "else", "enum", "extern", "float", "for", "goto", "if", "inline",
+	"int", "long", "register", "restrict", "return", "short", "signed",
+	"sizeof", "static", "struct", "switch", "typedef", "union", "unsigned",
+	"void", "volatile", "while", "_Alignas", "_Alignof", "_Atomic", "_Bool",
+	"_Complex", "_Generic", "_Imaginary", "_Noreturn", "_Static_assert",
+	"_Thread_local",
+}
+
+// CTrie maps each of CKeywords to its index.
+var CTrie = mustMakeTrie(CKeywords)
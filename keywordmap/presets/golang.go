@@ -0,0 +1,42 @@
+package presets
+
+// Indices of the Go keywords in GoKeywords and GoTrie.
+const (
+	GoBreak       = iota // break
+	GoCase               // case
+	GoChan               // chan
+	GoConst              // const
+	GoContinue           // continue
+	GoDefault            // default
+	GoDefer              // defer
+	GoElse               // else
+	GoFallthrough        // fallthrough
+	GoFor                // for
+	GoFunc               // func
+	GoGo                 // go
+	GoGoto               // goto
+	GoIf                 // if
+	GoImport             // import
+	GoInterface          // interface
+	GoMap                // map
+	GoPackage            // package
+	GoRange              // range
+	GoReturn             // return
+	GoSelect             // select
+	GoStruct             // struct
+	GoSwitch             // switch
+	GoType               // type
+	GoVar                // var
+)
+
+// GoKeywords lists the Go reserved keywords, as defined by the Go
+// specification.
+var GoKeywords = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else",
+	"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+	"map", "package", "range", "return", "select", "struct", "switch", "type",
+	"var",
+}
+
+// GoTrie maps each of GoKeywords to its index.
+var GoTrie = mustMakeTrie(GoKeywords)
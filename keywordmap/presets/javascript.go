@@ -0,0 +1,65 @@
+package presets
+
+// Indices of the JavaScript keywords in JSKeywords and JSTrie.
+const (
+	JSAwait      = iota // await
+	JSBreak             // break
+	JSCase              // case
+	JSCatch             // catch
+	JSClass             // class
+	JSConst             // const
+	JSContinue          // continue
+	JSDebugger          // debugger
+	JSDefault           // default
+	JSDelete            // delete
+	JSDo                // do
+	JSElse              // else
+	JSEnum              // enum
+	JSExport            // export
+	JSExtends           // extends
+	JSFalse             // false
+	JSFinally           // finally
+	JSFor               // for
+	JSFunction          // function
+	JSIf                // if
+	JSImplements        // implements
+	JSImport            // import
+	JSIn                // in
+	JSInstanceof        // instanceof
+	JSInterface         // interface
+	JSLet               // let
+	JSNew               // new
+	JSNull              // null
+	JSPackage           // package
+	JSPrivate           // private
+	JSProtected         // protected
+	JSPublic            // public
+	JSReturn            // return
+	JSStatic            // static
+	JSSuper             // super
+	JSSwitch            // switch
+	JSThis              // this
+	JSThrow             // throw
+	JSTrue              // true
+	JSTry               // try
+	JSTypeof            // typeof
+	JSVar               // var
+	JSVoid              // void
+	JSWhile             // while
+	JSWith              // with
+	JSYield             // yield
+)
+
+// JSKeywords lists the JavaScript reserved words, as defined by ECMAScript
+// 2023. Words that are reserved only in strict mode are included.
+var JSKeywords = []string{
+	"await", "break", "case", "catch", "class", "const", "continue", "debugger",
+	"default", "delete", "do", "else", "enum", "export", "extends", "false",
+	"finally", "for", "function", "if", "implements", "import", "in",
+	"instanceof", "interface", "let", "new", "null", "package", "private",
+	"protected", "public", "return", "static", "super", "switch", "this",
+	"throw", "true", "try", "typeof", "var", "void", "while", "with", "yield",
+}
+
+// JSTrie maps each of JSKeywords to its index.
+var JSTrie = mustMakeTrie(JSKeywords)
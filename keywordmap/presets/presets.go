@@ -0,0 +1,18 @@
+// Package presets provides ready-built keyword tries for some common
+// languages. For each language there is a list of keywords, a Trie mapping each
+// keyword to its index in the list, and a constant for each index.
+//
+// The tries are shared package-level values, so they must not be modified
+// (e.g. using keywordmap.AddToTrie). Use keywordmap.MakeTrie on a copy of the
+// keyword list to construct an extended set of keywords.
+package presets
+
+import "github.com/addrummond/deckwreck/keywordmap"
+
+func mustMakeTrie(keywords []string) keywordmap.Trie {
+	trie, ok := keywordmap.MakeTrie(keywords)
+	if !ok {
+		panic("Internal error in presets: could not construct trie")
+	}
+	return trie
+}
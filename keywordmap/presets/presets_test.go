@@ -0,0 +1,109 @@
+package presets
+
+import (
+	"go/scanner"
+	"go/token"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/addrummond/deckwreck/keywordmap"
+)
+
+func TestPresetIndices(t *testing.T) {
+	for name, p := range map[string]struct {
+		keywords []string
+		trie     keywordmap.Trie
+	}{
+		"Go":         {GoKeywords, GoTrie},
+		"C":          {CKeywords, CTrie},
+		"JavaScript": {JSKeywords, JSTrie},
+		"Python":     {PythonKeywords, PythonTrie},
+		"SQL":        {SQLKeywords, SQLTrie},
+	} {
+		for i, k := range p.keywords {
+			if keywordmap.KeywordIndex(p.trie, k) != i {
+				t.Errorf("%v: expecting index %v for '%v', got %v", name, i, k, keywordmap.KeywordIndex(p.trie, k))
+			}
+		}
+	}
+
+	if GoKeywords[GoFallthrough] != "fallthrough" || CKeywords[CStaticAssert] != "_Static_assert" ||
+		JSKeywords[JSInstanceof] != "instanceof" || PythonKeywords[PythonNonlocal] != "nonlocal" ||
+		SQLKeywords[SQLEndExec] != "END-EXEC" {
+		t.Errorf("Index constants do not match keyword lists")
+	}
+}
+
+func TestSQLFoldTrie(t *testing.T) {
+	for w, i := range map[string]int{"select": SQLSelect, "SeLeCt": SQLSelect, "end-exec": SQLEndExec, "current_date": SQLCurrentDate, "selects": -1, "": -1} {
+		if keywordmap.ByteClassKeywordIndex(&SQLFoldTrie, w) != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, w, keywordmap.ByteClassKeywordIndex(&SQLFoldTrie, w))
+		}
+	}
+}
+
+func TestGoAgreesWithGoToken(t *testing.T) {
+	for tok := token.Token(0); tok < 256; tok++ {
+		if tok.IsKeyword() && keywordmap.KeywordIndex(GoTrie, tok.String()) == -1 {
+			t.Errorf("Expecting '%v' to be in GoTrie", tok)
+		}
+	}
+
+	for _, w := range goCorpus(t) {
+		isKeyword := keywordmap.KeywordIndex(GoTrie, w) != -1
+		if isKeyword != token.Lookup(w).IsKeyword() {
+			t.Errorf("GoTrie disagrees with go/token about '%v'", w)
+		}
+		if isKeyword && GoKeywords[keywordmap.KeywordIndex(GoTrie, w)] != w {
+			t.Errorf("Wrong index for '%v'", w)
+		}
+	}
+}
+
+// goCorpus returns the identifiers and keywords in the Go source files of this
+// module, together with some random strings and near misses.
+func goCorpus(t *testing.T) []string {
+	files, err := filepath.Glob("../../*/*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Could not find Go source files: %v", err)
+	}
+
+	var corpus []string
+	fset := token.NewFileSet()
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("Could not read %v: %v", f, err)
+		}
+		var s scanner.Scanner
+		s.Init(fset.AddFile(f, -1, len(src)), src, nil, 0)
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if tok == token.IDENT || tok.IsKeyword() {
+				corpus = append(corpus, lit)
+			}
+		}
+	}
+
+	for _, k := range GoKeywords {
+		corpus = append(corpus, k[:len(k)-1], k+"s", strings.ToUpper(k), "_"+k)
+	}
+
+	r := rand.New(rand.NewSource(12345))
+	letters := "abcdefghijklmnopqrstuvwxyz"
+	for i := 0; i < 10000; i++ {
+		b := make([]byte, r.Intn(8)+1)
+		for j := range b {
+			b[j] = letters[r.Intn(len(letters))]
+		}
+		corpus = append(corpus, string(b))
+	}
+
+	return corpus
+}
@@ -0,0 +1,52 @@
+package presets
+
+// Indices of the Python keywords in PythonKeywords and PythonTrie.
+const (
+	PythonFalse    = iota // False
+	PythonNone            // None
+	PythonTrue            // True
+	PythonAnd             // and
+	PythonAs              // as
+	PythonAssert          // assert
+	PythonAsync           // async
+	PythonAwait           // await
+	PythonBreak           // break
+	PythonClass           // class
+	PythonContinue        // continue
+	PythonDef             // def
+	PythonDel             // del
+	PythonElif            // elif
+	PythonElse            // else
+	PythonExcept          // except
+	PythonFinally         // finally
+	PythonFor             // for
+	PythonFrom            // from
+	PythonGlobal          // global
+	PythonIf              // if
+	PythonImport          // import
+	PythonIn              // in
+	PythonIs              // is
+	PythonLambda          // lambda
+	PythonNonlocal        // nonlocal
+	PythonNot             // not
+	PythonOr              // or
+	PythonPass            // pass
+	PythonRaise           // raise
+	PythonReturn          // return
+	PythonTry             // try
+	PythonWhile           // while
+	PythonWith            // with
+	PythonYield           // yield
+)
+
+// PythonKeywords lists the Python keywords, as given by keyword.kwlist in
+// Python 3.12. Soft keywords such as 'match' and 'case' are not included.
+var PythonKeywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await", "break",
+	"class", "continue", "def", "del", "elif", "else", "except", "finally",
+	"for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal",
+	"not", "or", "pass", "raise", "return", "try", "while", "with", "yield",
+}
+
+// PythonTrie maps each of PythonKeywords to its index.
+var PythonTrie = mustMakeTrie(PythonKeywords)
@@ -0,0 +1,293 @@
+package presets
+
+import "github.com/addrummond/deckwreck/keywordmap"
+
+// Indices of the SQL keywords in SQLKeywords and SQLTrie.
+const (
+	SQLAbsolute         = iota // ABSOLUTE
+	SQLAction                  // ACTION
+	SQLAdd                     // ADD
+	SQLAll                     // ALL
+	SQLAllocate                // ALLOCATE
+	SQLAlter                   // ALTER
+	SQLAnd                     // AND
+	SQLAny                     // ANY
+	SQLAre                     // ARE
+	SQLAs                      // AS
+	SQLAsc                     // ASC
+	SQLAssertion               // ASSERTION
+	SQLAt                      // AT
+	SQLAuthorization           // AUTHORIZATION
+	SQLAvg                     // AVG
+	SQLBegin                   // BEGIN
+	SQLBetween                 // BETWEEN
+	SQLBit                     // BIT
+	SQLBitLength               // BIT_LENGTH
+	SQLBoth                    // BOTH
+	SQLBy                      // BY
+	SQLCascade                 // CASCADE
+	SQLCascaded                // CASCADED
+	SQLCase                    // CASE
+	SQLCast                    // CAST
+	SQLCatalog                 // CATALOG
+	SQLChar                    // CHAR
+	SQLCharacter               // CHARACTER
+	SQLCharLength              // CHAR_LENGTH
+	SQLCharacterLength         // CHARACTER_LENGTH
+	SQLCheck                   // CHECK
+	SQLClose                   // CLOSE
+	SQLCoalesce                // COALESCE
+	SQLCollate                 // COLLATE
+	SQLCollation               // COLLATION
+	SQLColumn                  // COLUMN
+	SQLCommit                  // COMMIT
+	SQLConnect                 // CONNECT
+	SQLConnection              // CONNECTION
+	SQLConstraint              // CONSTRAINT
+	SQLConstraints             // CONSTRAINTS
+	SQLContinue                // CONTINUE
+	SQLConvert                 // CONVERT
+	SQLCorresponding           // CORRESPONDING
+	SQLCount                   // COUNT
+	SQLCreate                  // CREATE
+	SQLCross                   // CROSS
+	SQLCurrent                 // CURRENT
+	SQLCurrentDate             // CURRENT_DATE
+	SQLCurrentTime             // CURRENT_TIME
+	SQLCurrentTimestamp        // CURRENT_TIMESTAMP
+	SQLCurrentUser             // CURRENT_USER
+	SQLCursor                  // CURSOR
+	SQLDate                    // DATE
+	SQLDay                     // DAY
+	SQLDeallocate              // DEALLOCATE
+	SQLDec                     // DEC
+	SQLDecimal                 // DECIMAL
+	SQLDeclare                 // DECLARE
+	SQLDefault                 // DEFAULT
+	SQLDeferrable              // DEFERRABLE
+	SQLDeferred                // DEFERRED
+	SQLDelete                  // DELETE
+	SQLDesc                    // DESC
+	SQLDescribe                // DESCRIBE
+	SQLDescriptor              // DESCRIPTOR
+	SQLDiagnostics             // DIAGNOSTICS
+	SQLDisconnect              // DISCONNECT
+	SQLDistinct                // DISTINCT
+	SQLDomain                  // DOMAIN
+	SQLDouble                  // DOUBLE
+	SQLDrop                    // DROP
+	SQLElse                    // ELSE
+	SQLEnd                     // END
+	SQLEndExec                 // END-EXEC
+	SQLEscape                  // ESCAPE
+	SQLExcept                  // EXCEPT
+	SQLException               // EXCEPTION
+	SQLExec                    // EXEC
+	SQLExecute                 // EXECUTE
+	SQLExists                  // EXISTS
+	SQLExternal                // EXTERNAL
+	SQLExtract                 // EXTRACT
+	SQLFalse                   // FALSE
+	SQLFetch                   // FETCH
+	SQLFirst                   // FIRST
+	SQLFloat                   // FLOAT
+	SQLFor                     // FOR
+	SQLForeign                 // FOREIGN
+	SQLFound                   // FOUND
+	SQLFrom                    // FROM
+	SQLFull                    // FULL
+	SQLGet                     // GET
+	SQLGlobal                  // GLOBAL
+	SQLGo                      // GO
+	SQLGoto                    // GOTO
+	SQLGrant                   // GRANT
+	SQLGroup                   // GROUP
+	SQLHaving                  // HAVING
+	SQLHour                    // HOUR
+	SQLIdentity                // IDENTITY
+	SQLImmediate               // IMMEDIATE
+	SQLIn                      // IN
+	SQLIndicator               // INDICATOR
+	SQLInitially               // INITIALLY
+	SQLInner                   // INNER
+	SQLInput                   // INPUT
+	SQLInsensitive             // INSENSITIVE
+	SQLInsert                  // INSERT
+	SQLInt                     // INT
+	SQLInteger                 // INTEGER
+	SQLIntersect               // INTERSECT
+	SQLInterval                // INTERVAL
+	SQLInto                    // INTO
+	SQLIs                      // IS
+	SQLIsolation               // ISOLATION
+	SQLJoin                    // JOIN
+	SQLKey                     // KEY
+	SQLLanguage                // LANGUAGE
+	SQLLast                    // LAST
+	SQLLeading                 // LEADING
+	SQLLeft                    // LEFT
+	SQLLevel                   // LEVEL
+	SQLLike                    // LIKE
+	SQLLocal                   // LOCAL
+	SQLLower                   // LOWER
+	SQLMatch                   // MATCH
+	SQLMax                     // MAX
+	SQLMin                     // MIN
+	SQLMinute                  // MINUTE
+	SQLModule                  // MODULE
+	SQLMonth                   // MONTH
+	SQLNames                   // NAMES
+	SQLNational                // NATIONAL
+	SQLNatural                 // NATURAL
+	SQLNchar                   // NCHAR
+	SQLNext                    // NEXT
+	SQLNo                      // NO
+	SQLNot                     // NOT
+	SQLNull                    // NULL
+	SQLNullif                  // NULLIF
+	SQLNumeric                 // NUMERIC
+	SQLOctetLength             // OCTET_LENGTH
+	SQLOf                      // OF
+	SQLOn                      // ON
+	SQLOnly                    // ONLY
+	SQLOpen                    // OPEN
+	SQLOption                  // OPTION
+	SQLOr                      // OR
+	SQLOrder                   // ORDER
+	SQLOuter                   // OUTER
+	SQLOutput                  // OUTPUT
+	SQLOverlaps                // OVERLAPS
+	SQLPad                     // PAD
+	SQLPartial                 // PARTIAL
+	SQLPosition                // POSITION
+	SQLPrecision               // PRECISION
+	SQLPrepare                 // PREPARE
+	SQLPreserve                // PRESERVE
+	SQLPrimary                 // PRIMARY
+	SQLPrior                   // PRIOR
+	SQLPrivileges              // PRIVILEGES
+	SQLProcedure               // PROCEDURE
+	SQLPublic                  // PUBLIC
+	SQLRead                    // READ
+	SQLReal                    // REAL
+	SQLReferences              // REFERENCES
+	SQLRelative                // RELATIVE
+	SQLRestrict                // RESTRICT
+	SQLRevoke                  // REVOKE
+	SQLRight                   // RIGHT
+	SQLRollback                // ROLLBACK
+	SQLRows                    // ROWS
+	SQLSchema                  // SCHEMA
+	SQLScroll                  // SCROLL
+	SQLSecond                  // SECOND
+	SQLSection                 // SECTION
+	SQLSelect                  // SELECT
+	SQLSession                 // SESSION
+	SQLSessionUser             // SESSION_USER
+	SQLSet                     // SET
+	SQLSize                    // SIZE
+	SQLSmallint                // SMALLINT
+	SQLSome                    // SOME
+	SQLSpace                   // SPACE
+	SQLSql                     // SQL
+	SQLSqlcode                 // SQLCODE
+	SQLSqlerror                // SQLERROR
+	SQLSqlstate                // SQLSTATE
+	SQLSubstring               // SUBSTRING
+	SQLSum                     // SUM
+	SQLSystemUser              // SYSTEM_USER
+	SQLTable                   // TABLE
+	SQLTemporary               // TEMPORARY
+	SQLThen                    // THEN
+	SQLTime                    // TIME
+	SQLTimestamp               // TIMESTAMP
+	SQLTimezoneHour            // TIMEZONE_HOUR
+	SQLTimezoneMinute          // TIMEZONE_MINUTE
+	SQLTo                      // TO
+	SQLTrailing                // TRAILING
+	SQLTransaction             // TRANSACTION
+	SQLTranslate               // TRANSLATE
+	SQLTranslation             // TRANSLATION
+	SQLTrim                    // TRIM
+	SQLTrue                    // TRUE
+	SQLUnion                   // UNION
+	SQLUnique                  // UNIQUE
+	SQLUnknown                 // UNKNOWN
+	SQLUpdate                  // UPDATE
+	SQLUpper                   // UPPER
+	SQLUsage                   // USAGE
+	SQLUser                    // USER
+	SQLUsing                   // USING
+	SQLValue                   // VALUE
+	SQLValues                  // VALUES
+	SQLVarchar                 // VARCHAR
+	SQLVarying                 // VARYING
+	SQLView                    // VIEW
+	SQLWhen                    // WHEN
+	SQLWhenever                // WHENEVER
+	SQLWhere                   // WHERE
+	SQLWith                    // WITH
+	SQLWork                    // WORK
+	SQLWrite                   // WRITE
+	SQLYear                    // YEAR
+	SQLZone                    // ZONE
+)
+
+// SQLKeywords lists the SQL reserved words, as defined by the SQL-92
+// standard.
+var SQLKeywords = []string{
+	"ABSOLUTE", "ACTION", "ADD", "ALL", "ALLOCATE", "ALTER", "AND", "ANY",
+	"ARE", "AS", "ASC", "ASSERTION", "AT", "AUTHORIZATION", "AVG", "BEGIN",
+	"BETWEEN", "BIT", "BIT_LENGTH", "BOTH", "BY", "CASCADE", "CASCADED", "CASE",
+	"CAST", "CATALOG", "CHAR", "CHARACTER", "CHAR_LENGTH", "CHARACTER_LENGTH",
+	"CHECK", "CLOSE", "COALESCE", "COLLATE", "COLLATION", "COLUMN", "COMMIT",
+	"CONNECT", "CONNECTION", "CONSTRAINT", "CONSTRAINTS", "CONTINUE", "CONVERT",
+	"CORRESPONDING", "COUNT", "CREATE", "CROSS", "CURRENT", "CURRENT_DATE",
+	"CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER", "CURSOR", "DATE",
+	"DAY", "DEALLOCATE", "DEC", "DECIMAL", "DECLARE", "DEFAULT", "DEFERRABLE",
+	"DEFERRED", "DELETE", "DESC", "DESCRIBE", "DESCRIPTOR", "DIAGNOSTICS",
+	"DISCONNECT", "DISTINCT", "DOMAIN", "DOUBLE", "DROP", "ELSE", "END",
+	"END-EXEC", "ESCAPE", "EXCEPT", "EXCEPTION", "EXEC", "EXECUTE", "EXISTS",
+	"EXTERNAL", "EXTRACT", "FALSE", "FETCH", "FIRST", "FLOAT", "FOR", "FOREIGN",
+	"FOUND", "FROM", "FULL", "GET", "GLOBAL", "GO", "GOTO", "GRANT", "GROUP",
+	"HAVING", "HOUR", "IDENTITY", "IMMEDIATE", "IN", "INDICATOR", "INITIALLY",
+	"INNER", "INPUT", "INSENSITIVE", "INSERT", "INT", "INTEGER", "INTERSECT",
+	"INTERVAL", "INTO", "IS", "ISOLATION", "JOIN", "KEY", "LANGUAGE", "LAST",
+	"LEADING", "LEFT", "LEVEL", "LIKE", "LOCAL", "LOWER", "MATCH", "MAX", "MIN",
+	"MINUTE", "MODULE", "MONTH", "NAMES", "NATIONAL", "NATURAL", "NCHAR",
+	"NEXT", "NO", "NOT", "NULL", "NULLIF", "NUMERIC", "OCTET_LENGTH", "OF",
+	"ON", "ONLY", "OPEN", "OPTION", "OR", "ORDER", "OUTER", "OUTPUT",
+	"OVERLAPS", "PAD", "PARTIAL", "POSITION", "PRECISION", "PREPARE",
+	"PRESERVE", "PRIMARY", "PRIOR", "PRIVILEGES", "PROCEDURE", "PUBLIC", "READ",
+	"REAL", "REFERENCES", "RELATIVE", "RESTRICT", "REVOKE", "RIGHT", "ROLLBACK",
+	"ROWS", "SCHEMA", "SCROLL", "SECOND", "SECTION", "SELECT", "SESSION",
+	"SESSION_USER", "SET", "SIZE", "SMALLINT", "SOME", "SPACE", "SQL",
+	"SQLCODE", "SQLERROR", "SQLSTATE", "SUBSTRING", "SUM", "SYSTEM_USER",
+	"TABLE", "TEMPORARY", "THEN", "TIME", "TIMESTAMP", "TIMEZONE_HOUR",
+	"TIMEZONE_MINUTE", "TO", "TRAILING", "TRANSACTION", "TRANSLATE",
+	"TRANSLATION", "TRIM", "TRUE", "UNION", "UNIQUE", "UNKNOWN", "UPDATE",
+	"UPPER", "USAGE", "USER", "USING", "VALUE", "VALUES", "VARCHAR", "VARYING",
+	"VIEW", "WHEN", "WHENEVER", "WHERE", "WITH", "WORK", "WRITE", "YEAR",
+	"ZONE",
+}
+
+// SQLTrie maps each of SQLKeywords to its index.
+var SQLTrie = mustMakeTrie(SQLKeywords)
+
+// SQLFoldTrie maps each of SQLKeywords to its index, ignoring the case of ASCII
+// letters (as SQL does). Use keywordmap.ByteClassKeywordIndex to query it.
+var SQLFoldTrie = func() keywordmap.ByteClassTrie {
+	var table keywordmap.ByteClassTable
+	for i := 0; i < 26; i++ {
+		table['a'+i] = uint8(i + 1)
+		table['A'+i] = uint8(i + 1)
+	}
+	table['_'] = 27
+	table['-'] = 28
+
+	trie, ok := keywordmap.MakeByteClassTrie(SQLKeywords, &table)
+	if !ok {
+		panic("Internal error in presets: could not construct trie")
+	}
+	return trie
+}()
@@ -0,0 +1,68 @@
+package keywordmap
+
+import "golang.org/x/exp/constraints"
+
+// LookupProfile maps each input that was looked up to the number of times that
+// it was looked up. Profiles are typically recorded using a
+// GenericProfilingTrie, but may be constructed by any other means (e.g. by
+// counting the identifiers in a corpus of source files).
+type LookupProfile map[string]int
+
+// ProfilingTrie is the recommended instantiation of GenericProfilingTrie.
+type ProfilingTrie = GenericProfilingTrie[uint16]
+
+// GenericProfilingTrie wraps a GenericTrie and records a LookupProfile of all
+// the lookups made through ProfilingKeywordIndex. It is not safe for
+// concurrent use. It should be constructed only via MakeProfilingTrie.
+type GenericProfilingTrie[I constraints.Unsigned] struct {
+	trie    GenericTrie[I]
+	profile LookupProfile
+}
+
+// MakeProfilingTrie returns a GenericProfilingTrie that wraps trie and has an
+// empty profile.
+func MakeProfilingTrie[I constraints.Unsigned](trie GenericTrie[I]) *GenericProfilingTrie[I] {
+	return &GenericProfilingTrie[I]{trie, make(LookupProfile)}
+}
+
+// ProfilingKeywordIndex returns KeywordIndex(trie, word) for the wrapped trie
+// and adds word to the profile. It is much slower than KeywordIndex and is
+// intended only for collecting profiles.
+func ProfilingKeywordIndex[T ByteIndexable, I constraints.Unsigned](pt *GenericProfilingTrie[I], word T) int {
+	pt.profile[string(word)]++
+	return KeywordIndex(pt.trie, word)
+}
+
+// GetProfile returns the profile recorded so far. The returned map is shared
+// with pt and continues to be updated by subsequent lookups.
+func GetProfile[I constraints.Unsigned](pt *GenericProfilingTrie[I]) LookupProfile {
+	return pt.profile
+}
+
+// OptimizeWithProfile works like Optimize, except that the sample of lookups is
+// given as a profile. The paths for frequently looked up inputs are placed next
+// to each other in memory. This applies both to keywords and to
+// non-keywords: a non-keyword's path is the path for its longest prefix present
+// in the trie, so frequently rejected prefixes are also made cheap to reach.
+func OptimizeWithProfile[I constraints.Unsigned](trie GenericTrie[I], layout Layout, profile LookupProfile) GenericTrie[I] {
+	if len(profile) == 0 {
+		return optimizeWithHits(trie, layout, nil)
+	}
+
+	hits := make([]int, len(trie.backingSlice)/nodeSize)
+	for w, n := range profile {
+		countHits(trie.backingSlice, w, n, hits)
+	}
+	return optimizeWithHits(trie, layout, hits)
+}
+
+// MakeProfiledTrie constructs a trie from a set of keywords (as
+// MakeGenericTrie does) and then lays it out according to profile (as
+// OptimizeWithProfile does).
+func MakeProfiledTrie[I constraints.Unsigned, T ByteIndexable](keywords []T, layout Layout, profile LookupProfile) (GenericTrie[I], bool) {
+	trie, ok := MakeGenericTrie[I](keywords)
+	if !ok {
+		return trie, false
+	}
+	return OptimizeWithProfile(trie, layout, profile), true
+}
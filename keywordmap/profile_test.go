@@ -0,0 +1,90 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestProfilingTrie(t *testing.T) {
+	pt := MakeProfilingTrie(mustMakeTrie(t, []string{"for", "if"}))
+
+	for _, w := range []string{"for", "x", "for", "if", "x", "x"} {
+		ProfilingKeywordIndex(pt, w)
+	}
+	if ProfilingKeywordIndex(pt, []byte("if")) != 1 {
+		t.Errorf("Expecting 'if' to be in trie")
+	}
+
+	profile := GetProfile(pt)
+	expected := LookupProfile{"for": 2, "x": 3, "if": 2}
+	if len(profile) != len(expected) {
+		t.Errorf("Expecting profile %v, got %v", expected, profile)
+	}
+	for w, n := range expected {
+		if profile[w] != n {
+			t.Errorf("Expecting count %v for '%v', got %v", n, w, profile[w])
+		}
+	}
+}
+
+func TestMakeProfiledTrie(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	plain := mustMakeTrie(t, td.Keywords)
+
+	pt := MakeProfilingTrie(plain)
+	for _, w := range getIdentifierMix(td.Keywords) {
+		ProfilingKeywordIndex(pt, w)
+	}
+
+	for _, layout := range []Layout{LayoutBreadthFirst, LayoutDepthFirst} {
+		for _, profile := range []LookupProfile{nil, GetProfile(pt)} {
+			trie, ok := MakeProfiledTrie[uint16](td.Keywords, layout, profile)
+			if !ok {
+				t.Fatalf("Expecting trie to be constructed successfully")
+			}
+			for _, w := range append(td.ToTest, "", "zzzzzzzzzzzz") {
+				if KeywordIndex(trie, w) != KeywordIndex(plain, w) {
+					t.Errorf("%v: expecting index %v for '%v', got %v", layout, KeywordIndex(plain, w), w, KeywordIndex(trie, w))
+				}
+			}
+		}
+	}
+}
+
+func TestOptimizeWithProfilePutsHotPathFirst(t *testing.T) {
+	trie := mustMakeTrie(t, []string{"a", "z"})
+
+	// 'zz' is not a keyword, but its rejected lookups pass through the nodes
+	// for 'z'.
+	opt := OptimizeWithProfile(trie, LayoutDepthFirst, LookupProfile{"a": 3, "zz": 5})
+	if opt.backingSlice[1*nodeSize+('z'>>4)] != 2 {
+		t.Errorf("Expecting first node after root to be on the path for 'z'")
+	}
+
+	opt = OptimizeWithProfile(trie, LayoutDepthFirst, LookupProfile{"a": 3, "zz": 2})
+	if opt.backingSlice[1*nodeSize+('a'>>4)] != 2 {
+		t.Errorf("Expecting first node after root to be on the path for 'a'")
+	}
+}
+
+func BenchmarkRandomProfiledTrie(b *testing.B) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	profile := make(LookupProfile)
+	for _, w := range td.ToTest {
+		profile[w]++
+	}
+	trie, ok := MakeProfiledTrie[uint16](td.Keywords, LayoutDepthFirst, profile)
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (KeywordIndex(trie, w) != -1) != td.InTrie[j] {
+				panic("Internal error [35] in benchmark")
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package keywordmap
+
+import (
+	"iter"
+
+	"golang.org/x/exp/constraints"
+)
+
+// IndexPolicy determines the index that a keyword is given in the trie
+// resulting from a set operation. leftIndex and rightIndex are the keyword's
+// indices in the left and right operands, or -1 if the keyword is absent from
+// the relevant operand. The keyword is omitted from the result if the policy
+// returns a negative value. The keyword slice is valid only for the duration of
+// the call.
+type IndexPolicy func(keyword []byte, leftIndex, rightIndex int) int
+
+// PreferLeft is an IndexPolicy that uses the index from the left operand
+// wherever the keyword is present in the left operand.
+func PreferLeft(_ []byte, leftIndex, rightIndex int) int {
+	if leftIndex != -1 {
+		return leftIndex
+	}
+	return rightIndex
+}
+
+// PreferRight is an IndexPolicy that uses the index from the right operand
+// wherever the keyword is present in the right operand.
+func PreferRight(_ []byte, leftIndex, rightIndex int) int {
+	if rightIndex != -1 {
+		return rightIndex
+	}
+	return leftIndex
+}
+
+// OffsetRight returns an IndexPolicy that behaves like PreferLeft except that
+// offset is added to the indices of keywords that are present only in the right
+// operand. This is useful for merging a set of extension keywords (indexed from
+// zero) into a core set of keywords.
+func OffsetRight(offset int) IndexPolicy {
+	return func(_ []byte, leftIndex, rightIndex int) int {
+		if leftIndex != -1 {
+			return leftIndex
+		}
+		return rightIndex + offset
+	}
+}
+
+// Union returns a trie containing every keyword present in either a or b. The
+// index of each keyword is determined by policy. The second return value is
+// false if the resulting trie could not be constructed (see MakeGenericTrie).
+func Union[I constraints.Unsigned](a, b GenericTrie[I], policy IndexPolicy) (GenericTrie[I], bool) {
+	return setOp(a, b, policy, func(l, r int) bool { return true })
+}
+
+// Intersection returns a trie containing every keyword present in both a and b.
+// The index of each keyword is determined by policy. The second return value is
+// false if the resulting trie could not be constructed (see MakeGenericTrie).
+func Intersection[I constraints.Unsigned](a, b GenericTrie[I], policy IndexPolicy) (GenericTrie[I], bool) {
+	return setOp(a, b, policy, func(l, r int) bool { return l != -1 && r != -1 })
+}
+
+// Difference returns a trie containing every keyword present in a but not in b.
+// The index of each keyword is determined by policy (which is always passed -1
+// as its rightIndex argument). The second return value is false if the
+// resulting trie could not be constructed (see MakeGenericTrie).
+func Difference[I constraints.Unsigned](a, b GenericTrie[I], policy IndexPolicy) (GenericTrie[I], bool) {
+	return setOp(a, b, policy, func(l, r int) bool { return l != -1 && r == -1 })
+}
+
+func setOp[I constraints.Unsigned](a, b GenericTrie[I], policy IndexPolicy, include func(l, r int) bool) (GenericTrie[I], bool) {
+	result := MakeEmptyTrie[I]()
+	ok := true
+	walkPair(a.backingSlice, b.backingSlice, func(keyword []byte, l, r int) bool {
+		if !include(l, r) {
+			return true
+		}
+		if i := policy(keyword, l, r); i >= 0 && !AddToTrie(&result, keyword, i) {
+			ok = false
+			return false
+		}
+		return true
+	})
+
+	if !ok {
+		return MakeEmptyTrie[I](), false
+	}
+	return result, true
+}
+
+// ChangeKind is the kind of a KeywordChange.
+type ChangeKind int
+
+const (
+	KeywordAdded     ChangeKind = iota // keyword present only in the new trie
+	KeywordRemoved                     // keyword present only in the old trie
+	KeywordReindexed                   // keyword present in both tries with different indices
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case KeywordAdded:
+		return "KeywordAdded"
+	case KeywordRemoved:
+		return "KeywordRemoved"
+	case KeywordReindexed:
+		return "KeywordReindexed"
+	default:
+		panic("Unrecognized ChangeKind")
+	}
+}
+
+// KeywordChange describes a difference between two tries. OldIndex is -1 for
+// KeywordAdded and NewIndex is -1 for KeywordRemoved.
+type KeywordChange struct {
+	Kind     ChangeKind
+	Keyword  string
+	OldIndex int
+	NewIndex int
+}
+
+// Diff returns a sequence of the keyword-level changes required to get from
+// oldTrie to newTrie. Changes are yielded in byte order of their keywords.
+// Keywords that are present in both tries with the same index are not
+// reported. The tries are walked together, so no intermediate list of keywords
+// is constructed.
+func Diff[I constraints.Unsigned](oldTrie, newTrie GenericTrie[I]) iter.Seq[KeywordChange] {
+	return func(yield func(KeywordChange) bool) {
+		walkPair(oldTrie.backingSlice, newTrie.backingSlice, func(keyword []byte, l, r int) bool {
+			var c KeywordChange
+			switch {
+			case l == r:
+				return true
+			case l == -1:
+				c = KeywordChange{KeywordAdded, string(keyword), l, r}
+			case r == -1:
+				c = KeywordChange{KeywordRemoved, string(keyword), l, r}
+			default:
+				c = KeywordChange{KeywordReindexed, string(keyword), l, r}
+			}
+			return yield(c)
+		})
+	}
+}
+
+// walkPair traverses the tries with backing slices a and b in parallel. It
+// calls f in byte order for each keyword that is present in at least one of
+// the tries, passing the keyword's index in each trie (or -1 if absent). The
+// traversal stops if f returns false. The keyword slice passed to f is reused
+// between calls.
+func walkPair[I constraints.Unsigned](a, b []I, f func(keyword []byte, ai, bi int) bool) {
+	walkPairHelper(a, b, 1, 1, 0, make([]byte, 0, 16), f)
+}
+
+// walk calls f in byte order for each keyword in the trie with backing slice
+// ba. The traversal stops if f returns false. The keyword slice passed to f is
+// reused between calls.
+func walk[I constraints.Unsigned](ba []I, f func(keyword []byte, i int) bool) {
+	walkPair(ba, ba, func(keyword []byte, i, _ int) bool { return f(keyword, i) })
+}
+
+func walkPairHelper[I constraints.Unsigned](a, b []I, offA, offB, depth int, buf []byte, f func(keyword []byte, ai, bi int) bool) bool {
+	// An offset of zero refers to the nowhere node, which has no children and
+	// no keyword. A trie that lacks the current prefix therefore contributes
+	// nothing without any special casing.
+
+	if depth%2 == 0 {
+		ai := int(a[offA*nodeSize+nodeSize-1]) - 1
+		bi := int(b[offB*nodeSize+nodeSize-1]) - 1
+		if (ai != -1 || bi != -1) && !f(buf, ai, bi) {
+			return false
+		}
+	}
+
+	for nib := 0; nib < 16; nib++ {
+		ca := int(a[offA*nodeSize+nib])
+		cb := int(b[offB*nodeSize+nib])
+		if ca == 0 && cb == 0 {
+			continue
+		}
+
+		nbuf := buf
+		if depth%2 == 0 {
+			nbuf = append(buf, byte(nib<<4))
+		} else {
+			nbuf[len(nbuf)-1] = nbuf[len(nbuf)-1]&0xF0 | byte(nib)
+		}
+
+		if !walkPairHelper(a, b, ca, cb, depth+1, nbuf, f) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,135 @@
+package keywordmap
+
+import (
+	"slices"
+	"testing"
+)
+
+func mustMakeTrie(t testing.TB, keywords []string) Trie {
+	trie, ok := MakeTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting trie to be constructed successfuly.")
+	}
+	return trie
+}
+
+func expectIndices(t *testing.T, trie Trie, expected map[string]int, absent []string) {
+	for k, i := range expected {
+		if got := KeywordIndex(trie, k); got != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, k, got)
+		}
+	}
+	for _, k := range absent {
+		if got := KeywordIndex(trie, k); got != -1 {
+			t.Errorf("Did not expect to find '%v' in trie (got index %v)", k, got)
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	a := mustMakeTrie(t, []string{"and", "for", "form"})
+	b := mustMakeTrie(t, []string{"for", "with", "fo"})
+
+	u, ok := Union(a, b, PreferLeft)
+	if !ok {
+		t.Fatalf("Expecting union to be constructed successfully")
+	}
+	expectIndices(t, u, map[string]int{"and": 0, "for": 1, "form": 2, "with": 1, "fo": 2}, []string{"f", "forms", "wit", ""})
+
+	u, ok = Union(a, b, PreferRight)
+	if !ok {
+		t.Fatalf("Expecting union to be constructed successfully")
+	}
+	expectIndices(t, u, map[string]int{"and": 0, "for": 0, "form": 2, "with": 1, "fo": 2}, nil)
+
+	u, ok = Union(a, b, OffsetRight(3))
+	if !ok {
+		t.Fatalf("Expecting union to be constructed successfully")
+	}
+	expectIndices(t, u, map[string]int{"and": 0, "for": 1, "form": 2, "with": 4, "fo": 5}, nil)
+}
+
+func TestIntersection(t *testing.T) {
+	a := mustMakeTrie(t, []string{"and", "for", "form"})
+	b := mustMakeTrie(t, []string{"for", "with", "form"})
+
+	i, ok := Intersection(a, b, PreferRight)
+	if !ok {
+		t.Fatalf("Expecting intersection to be constructed successfully")
+	}
+	expectIndices(t, i, map[string]int{"for": 0, "form": 2}, []string{"and", "with", "fo"})
+}
+
+func TestDifference(t *testing.T) {
+	a := mustMakeTrie(t, []string{"and", "for", "form"})
+	b := mustMakeTrie(t, []string{"for", "with"})
+
+	d, ok := Difference(a, b, PreferLeft)
+	if !ok {
+		t.Fatalf("Expecting difference to be constructed successfully")
+	}
+	expectIndices(t, d, map[string]int{"and": 0, "form": 2}, []string{"for", "with", "fo"})
+}
+
+func TestSetOpPolicyCanDropKeywords(t *testing.T) {
+	a := mustMakeTrie(t, []string{"and", "for", "form"})
+	b := mustMakeTrie(t, []string{"with"})
+
+	u, ok := Union(a, b, func(keyword []byte, l, r int) int {
+		if string(keyword) == "for" {
+			return -1
+		}
+		return PreferLeft(keyword, l, r)
+	})
+	if !ok {
+		t.Fatalf("Expecting union to be constructed successfully")
+	}
+	expectIndices(t, u, map[string]int{"and": 0, "form": 2, "with": 0}, []string{"for"})
+}
+
+func TestSetOpTooBig(t *testing.T) {
+	a := mustMakeTrie(t, []string{"a"})
+	_, ok := Union(a, a, func(_ []byte, _, _ int) int { return 70000 })
+	if ok {
+		t.Errorf("Expecting union to fail to be constructed")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldTrie := mustMakeTrie(t, []string{"and", "for", "form", "goto"})
+	newTrie := mustMakeTrie(t, []string{"and", "form", "for", "with"})
+
+	changes := slices.Collect(Diff(oldTrie, newTrie))
+	expected := []KeywordChange{
+		{KeywordReindexed, "for", 1, 2},
+		{KeywordReindexed, "form", 2, 1},
+		{KeywordRemoved, "goto", 3, -1},
+		{KeywordAdded, "with", -1, 3},
+	}
+	if !slices.Equal(changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, changes)
+	}
+
+	if n := len(slices.Collect(Diff(oldTrie, oldTrie))); n != 0 {
+		t.Errorf("Expecting no changes between identical tries, got %v", n)
+	}
+}
+
+func TestDiffEarlyExit(t *testing.T) {
+	oldTrie := MakeEmptyTrie[uint16]()
+	newTrie := mustMakeTrie(t, []string{"a", "b", "c"})
+
+	n := 0
+	for c := range Diff(oldTrie, newTrie) {
+		if c.Kind != KeywordAdded {
+			t.Errorf("Expecting only additions, got %v", c.Kind)
+		}
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("Expecting iteration to stop after two changes, got %v", n)
+	}
+}
@@ -0,0 +1,156 @@
+package keywordmap
+
+import (
+	"math"
+	"math/bits"
+
+	"golang.org/x/exp/constraints"
+)
+
+// SparseElem is the constraint on the element type of a GenericSparseTrie's
+// backing array. Elements must be wide enough to hold a 16-bit occupancy
+// bitmap.
+type SparseElem interface {
+	~uint16 | ~uint32 | ~uint64 | ~uint | ~uintptr
+}
+
+// SparseTrie is the recommended instantiation of GenericSparseTrie.
+type SparseTrie = GenericSparseTrie[uint16]
+
+// GenericSparseTrie is an alternative representation of a set of keywords that
+// uses considerably less memory than GenericTrie, at the cost of slightly
+// slower lookups. It should be constructed only via MakeGenericSparseTrie,
+// MakeSparseTrie or SparseTrieFromTrie.
+type GenericSparseTrie[I SparseElem] struct {
+	// Each node consists of (in order):
+	//
+	//     * A bitmap with bit n set if the node has a child for nibble n.
+	//
+	//     * 0 if no keyword terminates at this node, or 1 + i for the index i of
+	//       the relevant keyword.
+	//
+	//     * The offsets in the backing slice of each child node, in nibble order.
+	//       The offset of the child for nibble n is therefore found at position
+	//       popcount(bitmap & ((1 << n) - 1)) in this list.
+	//
+	// Nodes thus have a size of between 2 and 18 I-sized words. Most nodes in a
+	// typical keyword trie have one child, so they occupy 3 words rather than
+	// the 17 used by GenericTrie. The root node is at offset 0.
+	backingSlice []I
+}
+
+// MakeSparseTrie calls MakeGenericSparseTrie with the I type parameter set to
+// uint16 (the recommended default).
+func MakeSparseTrie[T ByteIndexable](keywords []T) (SparseTrie, bool) {
+	return MakeGenericSparseTrie[uint16](keywords)
+}
+
+// MakeGenericSparseTrie constructs a sparse trie from a set of keywords. It
+// behaves like MakeGenericTrie, except that far larger sets of keywords can be
+// accommodated for a given I.
+func MakeGenericSparseTrie[I SparseElem, T ByteIndexable](keywords []T) (GenericSparseTrie[I], bool) {
+	dense, ok := MakeGenericTrie[uint32](keywords)
+	if !ok {
+		return makeEmptySparseTrie[I](), false
+	}
+	return SparseTrieFromTrie[I](dense)
+}
+
+func makeEmptySparseTrie[I SparseElem]() GenericSparseTrie[I] {
+	return GenericSparseTrie[I]{make([]I, 2)}
+}
+
+// SparseTrieFromTrie converts a GenericTrie to a GenericSparseTrie containing
+// the same keywords with the same indices. The second return value is false if
+// the sparse trie is too large for I, in which case the returned trie is empty.
+func SparseTrieFromTrie[I SparseElem, J constraints.Unsigned](trie GenericTrie[J]) (GenericSparseTrie[I], bool) {
+	ba := trie.backingSlice
+	max := maxElem[I]()
+
+	// Nodes are laid out breadth first. The first pass computes the offset of
+	// each node.
+	order := []int{1}
+	offsets := make([]int, len(ba)/nodeSize)
+	size := 0
+	for i := 0; i < len(order); i++ {
+		old := order[i]
+		offsets[old] = size
+		size += 2
+		for nib := 0; nib < 16; nib++ {
+			if c := int(ba[old*nodeSize+nib]); c != 0 {
+				order = append(order, c)
+				size++
+			}
+		}
+	}
+
+	if size-1 > max {
+		return makeEmptySparseTrie[I](), false
+	}
+
+	sba := make([]I, size)
+	for _, old := range order {
+		o := offsets[old]
+		var bitmap I
+		k := 0
+		for nib := 0; nib < 16; nib++ {
+			if c := int(ba[old*nodeSize+nib]); c != 0 {
+				bitmap |= 1 << nib
+				sba[o+2+k] = I(offsets[c])
+				k++
+			}
+		}
+		sba[o] = bitmap
+
+		t := uint64(ba[old*nodeSize+nodeSize-1])
+		if t > uint64(max) {
+			return makeEmptySparseTrie[I](), false
+		}
+		sba[o+1] = I(t)
+	}
+
+	return GenericSparseTrie[I]{sba}, true
+}
+
+// SparseKeywordIndex returns the index of word in a sparse trie, or -1 if it is
+// not present.
+func SparseKeywordIndex[T ByteIndexable, I SparseElem](trie GenericSparseTrie[I], word T) int {
+	ba := trie.backingSlice
+
+	off := 0
+
+	for i := 0; i < len(word); i++ {
+		b := int(word[i])
+
+		bitmap := uint16(ba[off])
+		bit := uint16(1) << (b >> 4)
+		if bitmap&bit == 0 {
+			return -1
+		}
+		off = int(ba[off+2+bits.OnesCount16(bitmap&(bit-1))])
+
+		bitmap = uint16(ba[off])
+		bit = uint16(1) << (b & 0xF)
+		if bitmap&bit == 0 {
+			return -1
+		}
+		off = int(ba[off+2+bits.OnesCount16(bitmap&(bit-1))])
+	}
+
+	return int(ba[off+1]) - 1
+}
+
+// SparseTrieSize returns the number of elements in the backing array of a
+// sparse trie.
+func SparseTrieSize[I SparseElem](trie GenericSparseTrie[I]) int {
+	return len(trie.backingSlice)
+}
+
+// maxElem returns MIN(maximum value of I, maximum positive value of int).
+func maxElem[I constraints.Unsigned]() int {
+	m := uint64(^I(0))
+	if m > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(m)
+}
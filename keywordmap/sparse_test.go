@@ -0,0 +1,127 @@
+package keywordmap
+
+import (
+	"math/rand"
+	"testing"
+	"unsafe"
+)
+
+func TestSparseTrie(t *testing.T) {
+	keywords := []string{"debu", "with", "and", "for", "case", "to", "form"}
+	trie := mustMakeTrie(t, keywords)
+	sparse, ok := MakeSparseTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting sparse trie to be constructed successfully")
+	}
+
+	for _, w := range append(keywords, "", "a", "fo", "forms", "withx", "\xff", "de") {
+		if SparseKeywordIndex(sparse, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, SparseKeywordIndex(sparse, w))
+		}
+	}
+	if SparseKeywordIndex(sparse, []byte("form")) != 6 {
+		t.Errorf("Expecting 'form' to be in sparse trie")
+	}
+
+	if SparseTrieSize(sparse) >= len(trie.backingSlice) {
+		t.Errorf("Expecting sparse trie (%v) to be smaller than dense trie (%v)", SparseTrieSize(sparse), len(trie.backingSlice))
+	}
+}
+
+func TestSparseTrieRandom(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	sparse, ok := MakeSparseTrie(td.Keywords)
+	if !ok {
+		t.Fatalf("Expecting sparse trie to be constructed successfully")
+	}
+	for j, w := range td.ToTest {
+		if (SparseKeywordIndex(sparse, w) != -1) != td.InTrie[j] {
+			t.Errorf("Unexpected result for '%v'", w)
+		}
+	}
+	for i, w := range td.Keywords {
+		if SparseKeywordIndex(sparse, w) != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, w, SparseKeywordIndex(sparse, w))
+		}
+	}
+}
+
+func TestSparseTrieFitsMoreKeywords(t *testing.T) {
+	keywords := getIdentifiers(2000)
+	if _, ok := MakeTrie(keywords); ok {
+		t.Fatalf("Expecting dense trie to be too big")
+	}
+	sparse, ok := MakeSparseTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting sparse trie to be constructed successfully")
+	}
+	for _, w := range keywords {
+		i := SparseKeywordIndex(sparse, w)
+		if i == -1 || keywords[i] != w {
+			t.Errorf("Expecting to find '%v' in sparse trie", w)
+		}
+	}
+}
+
+func TestSparseTrieTooBig(t *testing.T) {
+	_, ok := MakeSparseTrie(getIdentifiers(20000))
+	if ok {
+		t.Errorf("Expecting sparse trie to fail to be constructed")
+	}
+	sparse, ok := MakeGenericSparseTrie[uint32](getIdentifiers(20000))
+	if !ok {
+		t.Errorf("Expecting uint32 sparse trie to be constructed successfully")
+	}
+	if SparseKeywordIndex(sparse, "") != -1 {
+		t.Errorf("Expecting empty string to be absent")
+	}
+}
+
+func TestEmptySparseTrie(t *testing.T) {
+	sparse, ok := MakeSparseTrie([]string{})
+	if !ok {
+		t.Fatalf("Expecting sparse trie to be constructed successfully")
+	}
+	for i := 0; i < 256; i++ {
+		if SparseKeywordIndex(sparse, []byte{byte(i)}) != -1 {
+			t.Errorf("Expecting one element byte array to be absent")
+		}
+	}
+}
+
+func BenchmarkRandomSparseTrie(b *testing.B) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie, ok := MakeSparseTrie(td.Keywords)
+	if !ok {
+		b.Errorf("Expecting trie to be constructed successfuly.")
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (SparseKeywordIndex(trie, w) != -1) != td.InTrie[j] {
+				panic("Internal error [20] in benchmark")
+			}
+		}
+	}
+
+	b.ReportMetric(float64(SparseTrieSize(trie)*int(unsafe.Sizeof(uint16(0)))), "trie-bytes")
+}
+
+func BenchmarkRandomDenseTrie(b *testing.B) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(b, td.Keywords)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (KeywordIndex(trie, w) != -1) != td.InTrie[j] {
+				panic("Internal error [21] in benchmark")
+			}
+		}
+	}
+
+	b.ReportMetric(float64(len(trie.backingSlice)*int(unsafe.Sizeof(uint16(0)))), "trie-bytes")
+}
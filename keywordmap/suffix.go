@@ -0,0 +1,87 @@
+package keywordmap
+
+import (
+	"slices"
+
+	"golang.org/x/exp/constraints"
+)
+
+// SuffixTrie is the recommended instantiation of GenericSuffixTrie.
+type SuffixTrie = GenericSuffixTrie[uint16]
+
+// GenericSuffixTrie is a trie in which each keyword is stored back to front.
+// It is useful for matching keywords at the end of an input, such as the
+// suffixes of numeric literals ("u", "ul", "ull", "f32"). It should be
+// constructed only via MakeGenericSuffixTrie, MakeSuffixTrie or
+// MakeEmptySuffixTrie.
+type GenericSuffixTrie[I constraints.Unsigned] struct {
+	trie GenericTrie[I]
+}
+
+// MakeSuffixTrie calls MakeGenericSuffixTrie with the I type parameter set to
+// uint16 (the recommended default).
+func MakeSuffixTrie[T ByteIndexable](keywords []T) (SuffixTrie, bool) {
+	return MakeGenericSuffixTrie[uint16](keywords)
+}
+
+// MakeGenericSuffixTrie constructs a suffix trie from a set of keywords. It
+// behaves like MakeGenericTrie.
+func MakeGenericSuffixTrie[I constraints.Unsigned, T ByteIndexable](keywords []T) (GenericSuffixTrie[I], bool) {
+	st := MakeEmptySuffixTrie[I]()
+	for wi, k := range keywords {
+		if !AddToSuffixTrie(&st, k, wi) {
+			return MakeEmptySuffixTrie[I](), false
+		}
+	}
+	return st, true
+}
+
+// MakeEmptySuffixTrie returns an empty suffix trie.
+func MakeEmptySuffixTrie[I constraints.Unsigned]() GenericSuffixTrie[I] {
+	return GenericSuffixTrie[I]{MakeEmptyTrie[I]()}
+}
+
+// AddToSuffixTrie adds word to a suffix trie. It behaves like AddToTrie.
+func AddToSuffixTrie[T ByteIndexable, I constraints.Unsigned](trie *GenericSuffixTrie[I], word T, wordIndex int) bool {
+	reversed := []byte(string(word))
+	slices.Reverse(reversed)
+	return AddToTrie(&trie.trie, reversed, wordIndex)
+}
+
+// SuffixKeywordIndex returns the index of word in the list of keywords passed
+// to MakeSuffixTrie/MakeGenericSuffixTrie, or -1 if it is not present. Only a
+// keyword equal to the whole of word is matched.
+func SuffixKeywordIndex[T ByteIndexable, I constraints.Unsigned](trie GenericSuffixTrie[I], word T) int {
+	ba := trie.trie.backingSlice
+
+	off := 1
+	for i := len(word) - 1; i >= 0; i-- {
+		off = stepByte(ba, off, word[i])
+		if off == 0 {
+			return -1
+		}
+	}
+
+	return int(ba[off*nodeSize+nodeSize-1]) - 1
+}
+
+// LongestSuffix finds the longest keyword that word ends with. It returns the
+// index of the keyword and the position in word where the keyword starts. If
+// word does not end with any keyword, it returns -1 and len(word).
+func LongestSuffix[T ByteIndexable, I constraints.Unsigned](trie GenericSuffixTrie[I], word T) (index, start int) {
+	ba := trie.trie.backingSlice
+
+	index, start = -1, len(word)
+	off := 1
+	for i := len(word) - 1; i >= 0; i-- {
+		off = stepByte(ba, off, word[i])
+		if off == 0 {
+			break
+		}
+		if ki := int(ba[off*nodeSize+nodeSize-1]) - 1; ki != -1 {
+			index, start = ki, i
+		}
+	}
+
+	return
+}
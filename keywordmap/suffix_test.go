@@ -0,0 +1,61 @@
+package keywordmap
+
+import "testing"
+
+func TestLongestSuffix(t *testing.T) {
+	st, ok := MakeSuffixTrie([]string{"u", "ul", "ull", "f32", "f64", "l"})
+	if !ok {
+		t.Fatalf("Expecting suffix trie to be constructed successfully")
+	}
+
+	cases := []struct {
+		input        string
+		index, start int
+	}{
+		{"123ull", 2, 3},
+		{"123ul", 1, 3},
+		{"123u", 0, 3},
+		{"123l", 5, 3},
+		{"123ll", 5, 4},
+		{"1.5f32", 3, 3},
+		{"f64", 4, 0},
+		{"1.5f16", -1, 6},
+		{"123", -1, 3},
+		{"", -1, 0},
+	}
+	for _, c := range cases {
+		index, start := LongestSuffix(st, c.input)
+		if index != c.index || start != c.start {
+			t.Errorf("%v: expecting (%v, %v), got (%v, %v)", c.input, c.index, c.start, index, start)
+		}
+		index, start = LongestSuffix(st, []byte(c.input))
+		if index != c.index || start != c.start {
+			t.Errorf("%v (as byte slice): expecting (%v, %v), got (%v, %v)", c.input, c.index, c.start, index, start)
+		}
+	}
+}
+
+func TestSuffixKeywordIndex(t *testing.T) {
+	keywords := []string{"debu", "with", "and", "for", "case", "to", "form"}
+	st, ok := MakeSuffixTrie(keywords)
+	if !ok {
+		t.Fatalf("Expecting suffix trie to be constructed successfully")
+	}
+	for i, k := range keywords {
+		if SuffixKeywordIndex(st, k) != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, k, SuffixKeywordIndex(st, k))
+		}
+	}
+	for _, w := range []string{"", "orm", "xform", "fo", "od"} {
+		if SuffixKeywordIndex(st, w) != -1 {
+			t.Errorf("Did not expect to find '%v' in suffix trie", w)
+		}
+	}
+}
+
+func TestSuffixTrieTooBig(t *testing.T) {
+	_, ok := MakeSuffixTrie(getIdentifiers(20000))
+	if ok {
+		t.Errorf("Expecting suffix trie to fail to be constructed")
+	}
+}
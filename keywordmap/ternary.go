@@ -0,0 +1,184 @@
+package keywordmap
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+const tstNodeSize = 4
+
+// TernaryTree is the recommended instantiation of GenericTernaryTree. A
+// backing array of uint32 suffices for sets of many millions of keywords.
+type TernaryTree = GenericTernaryTree[uint32]
+
+// GenericTernaryTree is a ternary search tree. Each node has a single split
+// byte, so its size does not depend on the number of distinct bytes that can
+// follow a prefix. This makes it much more compact than GenericTrie for large
+// sets of keywords (such as tables of thousands of builtin functions), at the
+// cost of a few more comparisons per byte of input. It should be constructed
+// only via MakeGenericTernaryTree, MakeTernaryTree or MakeEmptyTernaryTree.
+type GenericTernaryTree[I constraints.Unsigned] struct {
+	// splitBytes[n] is the split byte of node n.
+	splitBytes []byte
+	// Each node consists of (in order):
+	//
+	//     * The child for bytes less than the split byte.
+	//
+	//     * The child for the split byte, matching the next byte of the input.
+	//
+	//     * The child for bytes greater than the split byte.
+	//
+	//     * 0 if no keyword ends with the split byte at this node, or 1 + i for
+	//       the index i of the relevant keyword.
+	//
+	// As for GenericTrie, node 0 is a dummy node (so that 0 can be used for a
+	// missing child) and node 1 is the root, if the tree is non-empty.
+	backingSlice []I
+}
+
+// MakeTernaryTree calls MakeGenericTernaryTree with the I type parameter set to
+// uint32 (the recommended default).
+func MakeTernaryTree[T ByteIndexable](keywords []T) (TernaryTree, bool) {
+	return MakeGenericTernaryTree[uint32](keywords)
+}
+
+// MakeGenericTernaryTree constructs a ternary search tree from a set of
+// keywords. It behaves like MakeGenericTrie. The keywords are inserted in an
+// order that keeps the tree balanced, whatever the order of keywords.
+func MakeGenericTernaryTree[I constraints.Unsigned, T ByteIndexable](keywords []T) (GenericTernaryTree[I], bool) {
+	order := make([]int, len(keywords))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(string(keywords[a]), string(keywords[b]))
+	})
+	// As with AddToTrie, a repeated keyword takes the index of its last
+	// occurrence. The sort is stable, so this is the last of each run of equal
+	// keywords.
+	unique := order[:0]
+	for i, wi := range order {
+		if i+1 < len(order) && string(keywords[order[i+1]]) == string(keywords[wi]) {
+			continue
+		}
+		unique = append(unique, wi)
+	}
+
+	tree := MakeEmptyTernaryTree[I]()
+	// Inserting the median of each range before the rest of the range gives a
+	// balanced tree.
+	var insert func(lo, hi int) bool
+	insert = func(lo, hi int) bool {
+		if lo >= hi {
+			return true
+		}
+		mid := int(uint(lo+hi) >> 1)
+		wi := unique[mid]
+		return AddToTernaryTree(&tree, keywords[wi], wi) && insert(lo, mid) && insert(mid+1, hi)
+	}
+	if !insert(0, len(unique)) {
+		return MakeEmptyTernaryTree[I](), false
+	}
+
+	return tree, true
+}
+
+// MakeEmptyTernaryTree returns an empty ternary search tree.
+func MakeEmptyTernaryTree[I constraints.Unsigned]() GenericTernaryTree[I] {
+	return GenericTernaryTree[I]{make([]byte, 1), make([]I, tstNodeSize)}
+}
+
+// AddToTernaryTree adds word to a ternary search tree. It behaves like
+// AddToTrie.
+func AddToTernaryTree[T ByteIndexable, I constraints.Unsigned](tree *GenericTernaryTree[I], word T, wordIndex int) bool {
+	maxI := maxElem[I]()
+	if wordIndex+1 > maxI {
+		return false
+	}
+	if len(word) == 0 {
+		// The empty keyword is not supported (as for GenericTrie).
+		return true
+	}
+
+	// Check whether all the required nodes can be added before modifying the
+	// tree, so that a failed call leaves the tree unchanged.
+	if len(tree.splitBytes)+len(word) > maxI+1 {
+		return false
+	}
+
+	if len(tree.splitBytes) == 1 {
+		tree.addNode(word[0])
+	}
+
+	off := 1
+	for i := 0; ; {
+		b := word[i]
+		c := tree.splitBytes[off]
+		var link int
+		switch {
+		case b < c:
+			link = off * tstNodeSize
+		case b > c:
+			link = off*tstNodeSize + 2
+		default:
+			i++
+			if i == len(word) {
+				tree.backingSlice[off*tstNodeSize+3] = I(wordIndex + 1)
+				return true
+			}
+			link = off*tstNodeSize + 1
+		}
+
+		off = int(tree.backingSlice[link])
+		if off == 0 {
+			off = tree.addNode(word[i])
+			tree.backingSlice[link] = I(off)
+		}
+	}
+}
+
+func (tree *GenericTernaryTree[I]) addNode(b byte) int {
+	n := len(tree.splitBytes)
+	tree.splitBytes = append(tree.splitBytes, b)
+	tree.backingSlice = append(tree.backingSlice, 0, 0, 0, 0)
+	return n
+}
+
+// TernaryKeywordIndex returns the index of word in the list of keywords passed
+// to MakeTernaryTree/MakeGenericTernaryTree, or -1 if it is not present.
+func TernaryKeywordIndex[T ByteIndexable, I constraints.Unsigned](tree GenericTernaryTree[I], word T) int {
+	if len(word) == 0 || len(tree.splitBytes) == 1 {
+		return -1
+	}
+
+	splitBytes := tree.splitBytes
+	ba := tree.backingSlice
+
+	off := 1
+	for i := 0; ; {
+		b := word[i]
+		c := splitBytes[off]
+		switch {
+		case b < c:
+			off = int(ba[off*tstNodeSize])
+		case b > c:
+			off = int(ba[off*tstNodeSize+2])
+		default:
+			i++
+			if i == len(word) {
+				return int(ba[off*tstNodeSize+3]) - 1
+			}
+			off = int(ba[off*tstNodeSize+1])
+		}
+		if off == 0 {
+			return -1
+		}
+	}
+}
+
+// TernaryTreeSize returns the number of nodes in a ternary search tree.
+func TernaryTreeSize[I constraints.Unsigned](tree GenericTernaryTree[I]) int {
+	return len(tree.splitBytes) - 1
+}
@@ -0,0 +1,178 @@
+package keywordmap
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"unsafe"
+)
+
+func TestTernaryTree(t *testing.T) {
+	keywords := []string{"debu", "with", "and", "for", "case", "to", "form", "with"}
+	tree, ok := MakeTernaryTree(keywords)
+	if !ok {
+		t.Fatalf("Expecting ternary tree to be constructed successfully")
+	}
+	for i, k := range keywords {
+		expected := i
+		if k == "with" {
+			expected = 7
+		}
+		if TernaryKeywordIndex(tree, k) != expected {
+			t.Errorf("Expecting index %v for '%v', got %v", expected, k, TernaryKeywordIndex(tree, k))
+		}
+		if TernaryKeywordIndex(tree, []byte(k)) != expected {
+			t.Errorf("Expecting index %v for '%v' as byte slice, got %v", expected, k, TernaryKeywordIndex(tree, []byte(k)))
+		}
+	}
+	for _, w := range []string{"", "fo", "forms", "wit", "z", "\x00", "\xff"} {
+		if TernaryKeywordIndex(tree, w) != -1 {
+			t.Errorf("Did not expect to find '%v' in ternary tree", w)
+		}
+	}
+
+	empty := MakeEmptyTernaryTree[uint32]()
+	if TernaryKeywordIndex(empty, "for") != -1 {
+		t.Errorf("Did not expect to find 'for' in empty ternary tree")
+	}
+	if !AddToTernaryTree(&empty, "for", 3) || TernaryKeywordIndex(empty, "for") != 3 {
+		t.Errorf("Expecting 'for' to be added to empty ternary tree")
+	}
+	if !AddToTernaryTree(&empty, "", 4) || TernaryKeywordIndex(empty, "") != -1 {
+		t.Errorf("Did not expect the empty keyword to be added")
+	}
+}
+
+func TestRandomTernaryTree(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+	tree, ok := MakeTernaryTree(td.Keywords)
+	if !ok {
+		t.Fatalf("Expecting ternary tree to be constructed successfully")
+	}
+	for _, w := range append(td.ToTest, getIdentifiers(1000)...) {
+		if TernaryKeywordIndex(tree, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, TernaryKeywordIndex(tree, w))
+		}
+	}
+}
+
+func TestLargeTernaryTree(t *testing.T) {
+	ids := getIdentifiers(50000)
+	tree, ok := MakeTernaryTree(ids)
+	if !ok {
+		t.Fatalf("Expecting ternary tree to be constructed successfully")
+	}
+	last := make(map[string]int)
+	for i, id := range ids {
+		last[id] = i
+	}
+	for id, i := range last {
+		if TernaryKeywordIndex(tree, id) != i {
+			t.Errorf("Expecting index %v for '%v', got %v", i, id, TernaryKeywordIndex(tree, id))
+		}
+	}
+}
+
+func TestTernaryTreeTooBig(t *testing.T) {
+	_, ok := MakeGenericTernaryTree[uint8](getIdentifiers(100))
+	if ok {
+		t.Errorf("Expecting ternary tree to fail to be constructed")
+	}
+
+	tree := MakeEmptyTernaryTree[uint8]()
+	if !AddToTernaryTree(&tree, "abc", 0) {
+		t.Fatalf("Expecting 'abc' to be added")
+	}
+	if AddToTernaryTree(&tree, string(make([]byte, 253)), 1) {
+		t.Errorf("Expecting long keyword not to be added")
+	}
+	if TernaryTreeSize(tree) != 3 || TernaryKeywordIndex(tree, "abc") != 0 {
+		t.Errorf("Expecting failed addition to leave the tree unchanged")
+	}
+}
+
+// getTernaryBenchmarkData returns n keywords and a set of 1000 words to look
+// up, about half of which are keywords.
+func getTernaryBenchmarkData(n int) (keywords, toTest []string) {
+	ids := getIdentifiers(n + 500)
+	keywords = ids[:n]
+	r := rand.New(rand.NewSource(randSeed))
+	for i := 0; i < 500; i++ {
+		toTest = append(toTest, keywords[r.Intn(n)], ids[n+i])
+	}
+	return
+}
+
+var ternaryBenchmarkSizes = []int{100, 1000, 10000, 50000}
+
+func BenchmarkTernaryTree(b *testing.B) {
+	for _, n := range ternaryBenchmarkSizes {
+		keywords, toTest := getTernaryBenchmarkData(n)
+		tree, ok := MakeTernaryTree(keywords)
+		if !ok {
+			b.Fatalf("Expecting ternary tree to be constructed successfully")
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			found := 0
+			for i := 0; i < b.N; i++ {
+				for _, w := range toTest {
+					if TernaryKeywordIndex(tree, w) != -1 {
+						found++
+					}
+				}
+			}
+			if found == 0 {
+				panic("Internal error [40] in benchmark")
+			}
+			b.ReportMetric(float64(TernaryTreeSize(tree)*(1+tstNodeSize*int(unsafe.Sizeof(uint32(0))))), "tree-bytes")
+		})
+	}
+}
+
+func BenchmarkTernaryTreeMap(b *testing.B) {
+	for _, n := range ternaryBenchmarkSizes {
+		keywords, toTest := getTernaryBenchmarkData(n)
+		m := make(map[string]int, n)
+		for i, k := range keywords {
+			m[k] = i
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			found := 0
+			for i := 0; i < b.N; i++ {
+				for _, w := range toTest {
+					if _, ok := m[w]; ok {
+						found++
+					}
+				}
+			}
+			if found == 0 {
+				panic("Internal error [41] in benchmark")
+			}
+		})
+	}
+}
+
+func BenchmarkTernaryTreeTrie(b *testing.B) {
+	for _, n := range ternaryBenchmarkSizes {
+		keywords, toTest := getTernaryBenchmarkData(n)
+		trie, ok := MakeGenericTrie[uint32](keywords)
+		if !ok {
+			b.Fatalf("Expecting trie to be constructed successfully")
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			found := 0
+			for i := 0; i < b.N; i++ {
+				for _, w := range toTest {
+					if KeywordIndex(trie, w) != -1 {
+						found++
+					}
+				}
+			}
+			if found == 0 {
+				panic("Internal error [42] in benchmark")
+			}
+			b.ReportMetric(float64(len(trie.backingSlice)*int(unsafe.Sizeof(uint32(0)))), "trie-bytes")
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package keywordmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+// ErrMalformedTrie is returned (wrapped) by OpenTrieView if its input is not a
+// valid encoded trie.
+var ErrMalformedTrie = errors.New("malformed trie data")
+
+// TrieView is the recommended instantiation of GenericTrieView.
+type TrieView = GenericTrieView[uint16]
+
+// GenericTrieView is a read-only trie that performs lookups directly on the
+// little-endian encoding of a GenericTrie[I]'s backing array. It can be used
+// with data embedded via go:embed, or with a memory-mapped file, without
+// copying or decoding the data. It should be constructed only via
+// OpenTrieView.
+type GenericTrieView[I constraints.Unsigned] struct {
+	data []byte
+}
+
+// EncodeTrie returns the little-endian encoding of a trie's backing array. The
+// result can be passed to OpenTrieView.
+func EncodeTrie[I constraints.Unsigned](trie GenericTrie[I]) []byte {
+	size := int(unsafe.Sizeof(I(0)))
+	data := make([]byte, len(trie.backingSlice)*size)
+	for i, v := range trie.backingSlice {
+		for j := 0; j < size; j++ {
+			data[i*size+j] = byte(uint64(v) >> (8 * j))
+		}
+	}
+	return data
+}
+
+// OpenTrieView validates data and returns a view over it. The data must have
+// been produced by EncodeTrie with the same I type parameter. Validation ensures
+// that lookups cannot read out of bounds, so it is safe to open data from an
+// untrusted source. The data must not be modified while the view is in use.
+func OpenTrieView[I constraints.Unsigned](data []byte) (GenericTrieView[I], error) {
+	v := GenericTrieView[I]{data}
+	size := int(unsafe.Sizeof(I(0)))
+
+	if len(data)%(size*nodeSize) != 0 {
+		return GenericTrieView[I]{}, fmt.Errorf("%w: length %v is not a multiple of the node size", ErrMalformedTrie, len(data))
+	}
+	nNodes := len(data) / (size * nodeSize)
+	if nNodes < 2 {
+		return GenericTrieView[I]{}, fmt.Errorf("%w: too few nodes (%v)", ErrMalformedTrie, nNodes)
+	}
+
+	for i := 0; i < nodeSize; i++ {
+		if v.at(i) != 0 {
+			return GenericTrieView[I]{}, fmt.Errorf("%w: initial node is not empty", ErrMalformedTrie)
+		}
+	}
+
+	for n := 1; n < nNodes; n++ {
+		for nib := 0; nib < 16; nib++ {
+			if c := v.at(n*nodeSize + nib); c < 0 || c >= nNodes {
+				return GenericTrieView[I]{}, fmt.Errorf("%w: node %v has out of range child %v", ErrMalformedTrie, n, c)
+			}
+		}
+		if v.at(n*nodeSize+nodeSize-1) < 0 {
+			return GenericTrieView[I]{}, fmt.Errorf("%w: node %v has out of range keyword index", ErrMalformedTrie, n)
+		}
+	}
+
+	return v, nil
+}
+
+// at returns the ith element of the encoded backing array. Values too large for
+// an int are returned as -1.
+func (v GenericTrieView[I]) at(i int) int {
+	switch unsafe.Sizeof(I(0)) {
+	case 1:
+		return int(v.data[i])
+	case 2:
+		return int(binary.LittleEndian.Uint16(v.data[i*2:]))
+	case 4:
+		return int(binary.LittleEndian.Uint32(v.data[i*4:]))
+	default:
+		x := binary.LittleEndian.Uint64(v.data[i*8:])
+		if x > uint64(maxElem[uint64]()) {
+			return -1
+		}
+		return int(x)
+	}
+}
+
+// ViewKeywordIndex returns the index of word in the trie that the view was
+// encoded from, or -1 if it is not present.
+func ViewKeywordIndex[T ByteIndexable, I constraints.Unsigned](view GenericTrieView[I], word T) int {
+	off := 1
+
+	for i := 0; i < len(word); i++ {
+		b := int(word[i])
+
+		// As in KeywordIndex, a failed lookup loops round the nowhere node.
+		off = view.at(off*nodeSize + (b >> 4))
+		off = view.at(off*nodeSize + (b & 0xF))
+
+		if off == 0 {
+			return -1
+		}
+	}
+
+	return view.at(off*nodeSize+nodeSize-1) - 1
+}
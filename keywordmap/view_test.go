@@ -0,0 +1,104 @@
+package keywordmap
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestTrieView(t *testing.T) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	trie := mustMakeTrie(t, td.Keywords)
+
+	data := EncodeTrie(trie)
+	if len(data) != len(trie.backingSlice)*2 {
+		t.Errorf("Expecting %v bytes of data, got %v", len(trie.backingSlice)*2, len(data))
+	}
+	view, err := OpenTrieView[uint16](data)
+	if err != nil {
+		t.Fatalf("Unexpected error opening view: %v", err)
+	}
+
+	for _, w := range append(td.ToTest, "", "zzzzzzzzzzzz", "\xff") {
+		if ViewKeywordIndex(view, w) != KeywordIndex(trie, w) {
+			t.Errorf("Expecting index %v for '%v', got %v", KeywordIndex(trie, w), w, ViewKeywordIndex(view, w))
+		}
+	}
+}
+
+func TestTrieViewWidths(t *testing.T) {
+	keywords := []string{"debu", "with", "and", "for", "case", "to", "form"}
+
+	t8, ok := MakeGenericTrie[uint8]([]string{"to", "a"})
+	if !ok {
+		t.Fatalf("Expecting uint8 trie to be constructed successfully")
+	}
+	v8, err := OpenTrieView[uint8](EncodeTrie(t8))
+	if err != nil {
+		t.Fatalf("Unexpected error opening view: %v", err)
+	}
+	if ViewKeywordIndex(v8, "a") != 1 || ViewKeywordIndex(v8, "t") != -1 {
+		t.Errorf("Unexpected results for uint8 view")
+	}
+
+	t32, _ := MakeGenericTrie[uint32](keywords)
+	v32, err := OpenTrieView[uint32](EncodeTrie(t32))
+	if err != nil {
+		t.Fatalf("Unexpected error opening view: %v", err)
+	}
+	var ba64 []uint64
+	for _, x := range GetBackingSlice(t32) {
+		ba64 = append(ba64, uint64(x))
+	}
+	t64 := MakeTrieFromBackingSlice(ba64)
+	v64, err := OpenTrieView[uint64](EncodeTrie(t64))
+	if err != nil {
+		t.Fatalf("Unexpected error opening view: %v", err)
+	}
+	for i, w := range keywords {
+		if ViewKeywordIndex(v32, w) != i || ViewKeywordIndex(v64, []byte(w)) != i {
+			t.Errorf("Expecting index %v for '%v'", i, w)
+		}
+	}
+}
+
+func TestTrieViewRejectsMalformedData(t *testing.T) {
+	valid := EncodeTrie(mustMakeTrie(t, []string{"for", "if"}))
+
+	outOfRange := append([]byte{}, valid...)
+	outOfRange[2*(nodeSize+3)] = 0xFF
+
+	dirtyNowhere := append([]byte{}, valid...)
+	dirtyNowhere[0] = 1
+
+	for name, data := range map[string][]byte{
+		"empty":         {},
+		"odd length":    valid[:len(valid)-1],
+		"one node":      valid[:2*nodeSize],
+		"out of range":  outOfRange,
+		"dirty nowhere": dirtyNowhere,
+		"partial node":  valid[:len(valid)-2],
+	} {
+		if _, err := OpenTrieView[uint16](data); !errors.Is(err, ErrMalformedTrie) {
+			t.Errorf("%v: expecting ErrMalformedTrie, got %v", name, err)
+		}
+	}
+}
+
+func BenchmarkRandomTrieView(b *testing.B) {
+	td := getRandomTestData(rand.New(rand.NewSource(randSeed)))
+	view, err := OpenTrieView[uint16](EncodeTrie(mustMakeTrie(b, td.Keywords)))
+	if err != nil {
+		b.Errorf("Unexpected error opening view: %v", err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, w := range td.ToTest {
+			if (ViewKeywordIndex(view, w) != -1) != td.InTrie[j] {
+				panic("Internal error [36] in benchmark")
+			}
+		}
+	}
+}
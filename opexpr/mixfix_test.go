@@ -0,0 +1,408 @@
+package opexpr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var (
+	testTernary    = &MixfixOperator{Parts: []string{"?", ":"}, LeftHole: true, RightHole: true, Precedence: 5, Assoc: RightAssoc}
+	testIfThenElse = &MixfixOperator{Parts: []string{"if", "then", "else"}, RightHole: true, Precedence: 6}
+	testBetween    = &MixfixOperator{Parts: []string{"BETWEEN", "AND"}, LeftHole: true, RightHole: true, Precedence: 3}
+	testFor        = &MixfixOperator{Parts: []string{"for", "do", "done"}}
+)
+
+var testMixfixOperators = []*MixfixOperator{testTernary, testIfThenElse, testBetween, testFor}
+
+func testStartsMixfix(s string) *MixfixOperator {
+	for _, op := range testMixfixOperators {
+		if op.Parts[0] == s {
+			return op
+		}
+	}
+	return nil
+}
+
+func testMixfixPart(s string, op *MixfixOperator) int {
+	for i, p := range op.Parts {
+		if p == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// showMixfix shows a mixfix node using '⦃' and '⦄' as delimiters. Missing
+// parts are shown as '_' and missing operands as '·'.
+func showMixfix(leftHole bool, parts, operands []string) string {
+	var o strings.Builder
+	o.WriteString("⦃")
+	for i, p := range parts {
+		if i > 0 || leftHole {
+			o.WriteString(operands[0])
+			o.WriteByte(' ')
+			operands = operands[1:]
+		}
+		if p == "" {
+			p = "_"
+		}
+		o.WriteString(p)
+		if len(operands) > 0 {
+			o.WriteByte(' ')
+		}
+	}
+	if len(operands) > 0 {
+		o.WriteString(operands[0])
+	}
+	o.WriteString("⦄")
+	return o.String()
+}
+
+// mixfixElement is a StringElement that supports the mixfix operators in
+// testMixfixOperators. 'AND' is also an infix operator.
+type mixfixElement struct {
+	StringElement
+}
+
+func makeMixfixElements(s string) []mixfixElement {
+	var elems []mixfixElement
+	for _, se := range MakeStringElements(s) {
+		elems = append(elems, mixfixElement{se})
+	}
+	return elems
+}
+
+func (e mixfixElement) ExpressionKind(hasExpressionToLeft bool) ExpressionKind {
+	if e.StringElement == "AND" {
+		return BinaryLeftAssoc
+	}
+	return e.StringElement.ExpressionKind(hasExpressionToLeft)
+}
+
+func (e mixfixElement) Precedence(hasExpressionToLeft bool) int {
+	if e.StringElement == "AND" {
+		return 4
+	}
+	return e.StringElement.Precedence(hasExpressionToLeft)
+}
+
+func (e mixfixElement) MakeNode(arg1, arg2 *SimpleNode[mixfixElement]) *SimpleNode[mixfixElement] {
+	return &SimpleNode[mixfixElement]{arg1, arg2, e}
+}
+
+func (e mixfixElement) MakeErrorNode(pe *ParseError[mixfixElement], arg1, arg2 *SimpleNode[mixfixElement]) *SimpleNode[mixfixElement] {
+	se := StringElement(fmt.Sprintf("@error:%v", ShowParseError(pe)))
+	return &SimpleNode[mixfixElement]{arg1, arg2, mixfixElement{se}}
+}
+
+func (e mixfixElement) StartsMixfix(hasExpressionToLeft bool) *MixfixOperator {
+	return testStartsMixfix(string(e.StringElement))
+}
+
+func (e mixfixElement) MixfixPart(op *MixfixOperator) int {
+	return testMixfixPart(string(e.StringElement), op)
+}
+
+func (e mixfixElement) MakeMixfixNode(parts []mixfixElement, operands []*SimpleNode[mixfixElement]) *SimpleNode[mixfixElement] {
+	var ps, os []string
+	for _, p := range parts {
+		ps = append(ps, string(p.StringElement))
+	}
+	for _, o := range operands {
+		if o == nil {
+			os = append(os, "·")
+		} else {
+			os = append(os, ShowSimpleNode(o))
+		}
+	}
+	op := testStartsMixfix(string(e.StringElement))
+	return &SimpleNode[mixfixElement]{nil, nil, mixfixElement{StringElement(showMixfix(op.LeftHole, ps, os))}}
+}
+
+func testMixfix(t *testing.T, input, output string, errors ...string) {
+	t.Logf("Input: %v\n", input)
+
+	pool := MakeNodePool[SimpleNode[mixfixElement], mixfixElement](32)
+	root, errs := ParseSlice(makeMixfixElements(input), pool)
+	if o := ShowSimpleNode(root); o != output {
+		t.Errorf("Expected output: %v\nGot: %v\n", output, o)
+	}
+	got := make([]string, 0)
+	for _, pe := range errs {
+		got = append(got, pe.Error())
+	}
+	if strings.Join(got, "; ") != strings.Join(errors, "; ") {
+		t.Errorf("Expected errors: %v\nGot: %v\n", errors, got)
+	}
+}
+
+func TestMixfix(t *testing.T) {
+	testMixfix(t, "c ? a : b", "⦃c ? a : b⦄")
+	testMixfix(t, "c ? a : d ? e : f", "⦃c ? a : ⦃d ? e : f⦄⦄")
+	testMixfix(t, "c ? a ? b : d : e", "⦃c ? ⦃a ? b : d⦄ : e⦄")
+	testMixfix(t, "x + c ? a + b : d + e", "⦃⎡x + c⎦ ? ⎡a + b⎦ : ⎡d + e⎦⦄")
+	testMixfix(t, "if c then a + b else d * e", "⦃if c then ⎡a + b⎦ else ⎡d * e⎦⦄")
+	testMixfix(t, "if if c then d else e then a else b", "⦃if ⦃if c then d else e⦄ then a else b⦄")
+	testMixfix(t, "a BETWEEN x AND y AND z", "⎡⦃a BETWEEN x AND y⦄ AND z⎦")
+	testMixfix(t, "a AND b BETWEEN x AND y", "⎡a AND ⦃b BETWEEN x AND y⦄⎦")
+	testMixfix(t, "a BETWEEN ( x AND y ) AND z", "⦃a BETWEEN (⎡x AND y⎦) AND z⦄")
+	testMixfix(t, "( c ? a : b ) + 1", "⎡(⦃c ? a : b⦄) + 1⎦")
+	testMixfix(t, "f [[ c ? a : b ] + 1", "⎡⎡f [[ ⦃c ? a : b⦄⎦ + 1⎦")
+	testMixfix(t, "for x do y done + 1", "⎡⦃for x do y done⦄ + 1⎦")
+	testMixfix(t, "! for x do y done #", "⎡⎡!⦃for x do y done⦄⎦#⎦")
+}
+
+func TestMixfixErrors(t *testing.T) {
+	testMixfix(t, "c ? a", "⎡⦃c ? a _ ·⦄@error:ParseErrorMissingMixfixPart@a⎦", "ParseErrorMissingMixfixPart at element 2 (a) for element 1 (?)")
+	testMixfix(t, "if c else a", "⎡⦃if c _ · else a⦄@error:ParseErrorWrongMixfixPart@else⎦", "ParseErrorWrongMixfixPart at element 2 (else) for element 0 (if)")
+	// The repeated 'then' is ignored.
+	testMixfix(t, "if c then a then b", "⎡⦃if c then ⎡a @error:ParseErrorUnexpectedValue@b b⎦ _ ·⦄@error:ParseErrorWrongMixfixPart@then⎦", "ParseErrorWrongMixfixPart at element 4 (then) for element 0 (if)", "ParseErrorUnexpectedValue at element 5 (b)", "ParseErrorMissingMixfixPart at element 5 (b) for element 0 (if)")
+	testMixfix(t, "( c ? a ) + 1", "⎡(⎡⦃c ? a _ ·⦄@error:ParseErrorMissingMixfixPart@)⎦) + 1⎦", "ParseErrorMissingMixfixPart at element 4 ()) for element 2 (?)")
+	testMixfix(t, "c ? : b", "⦃c ? @error:ParseErrorUnexpectedOperator@: : b⦄", "ParseErrorUnexpectedOperator at element 2 (:)")
+	testMixfix(t, "c ? a :", "⦃c ? a : @error:ParseErrorUnexpectedOperator@:⦄", "ParseErrorUnexpectedOperator at element 3 (:)")
+	// A later part is an ordinary element when its operator isn't open.
+	testMixfix(t, "a : b", "⎡a : b⎦")
+}
+
+// invalidMixfixElement is a mixfixElement that declares invalid mixfix
+// operators: 'x' starts an operator with only one part, and ':' claims an out
+// of range part index for the ternary operator.
+type invalidMixfixElement struct {
+	mixfixElement
+}
+
+var testOnePart = &MixfixOperator{Parts: []string{"x"}}
+
+func (e invalidMixfixElement) MakeNode(arg1, arg2 *SimpleNode[invalidMixfixElement]) *SimpleNode[invalidMixfixElement] {
+	return &SimpleNode[invalidMixfixElement]{arg1, arg2, e}
+}
+
+func (e invalidMixfixElement) MakeErrorNode(pe *ParseError[invalidMixfixElement], arg1, arg2 *SimpleNode[invalidMixfixElement]) *SimpleNode[invalidMixfixElement] {
+	se := StringElement(fmt.Sprintf("@error:%v", pe.Kind))
+	return &SimpleNode[invalidMixfixElement]{arg1, arg2, invalidMixfixElement{mixfixElement{se}}}
+}
+
+func (e invalidMixfixElement) StartsMixfix(hasExpressionToLeft bool) *MixfixOperator {
+	if e.StringElement == "x" {
+		return testOnePart
+	}
+	return e.mixfixElement.StartsMixfix(hasExpressionToLeft)
+}
+
+func (e invalidMixfixElement) MixfixPart(op *MixfixOperator) int {
+	if e.StringElement == ":" && op == testTernary {
+		return 2
+	}
+	return e.mixfixElement.MixfixPart(op)
+}
+
+func (e invalidMixfixElement) MakeMixfixNode(parts []invalidMixfixElement, operands []*SimpleNode[invalidMixfixElement]) *SimpleNode[invalidMixfixElement] {
+	return &SimpleNode[invalidMixfixElement]{operands[0], nil, invalidMixfixElement{mixfixElement{StringElement(parts[0].StringElement)}}}
+}
+
+func TestInvalidMixfixOperators(t *testing.T) {
+	pool := MakeNodePool[SimpleNode[invalidMixfixElement], invalidMixfixElement](32)
+	parse := func(input string) (string, int) {
+		var elems []invalidMixfixElement
+		for _, e := range makeMixfixElements(input) {
+			elems = append(elems, invalidMixfixElement{e})
+		}
+		root, errs := ParseSlice(elems, pool)
+		return ShowSimpleNode(root), len(errs)
+	}
+
+	// The one-part operator is ignored, so 'x' is an ordinary value.
+	if o, n := parse("x + a"); o != "⎡x + a⎦" || n != 0 {
+		t.Errorf("Unexpected output %v (%v errors)\n", o, n)
+	}
+	if o, n := parse("x"); o != "x" || n != 0 {
+		t.Errorf("Unexpected output %v (%v errors)\n", o, n)
+	}
+	// ':' isn't recognized as a part, so the ternary operator is incomplete.
+	if _, n := parse("c ? a : b"); n == 0 {
+		t.Errorf("Expected errors for incomplete ternary operator\n")
+	}
+}
+
+// spannedMixfixElement is a mixfixElement that implements Spanned,
+// SpannedMixfixBuilder and SpannedMissingOperandBuilder. The nodes that it
+// builds record the span of their subtree.
+type spannedMixfixElement struct {
+	mixfixElement
+	start, end int
+}
+
+func makeSpannedMixfixElements(s string) []spannedMixfixElement {
+	var elems []spannedMixfixElement
+	for _, e := range makeSpannedElements(s) {
+		elems = append(elems, spannedMixfixElement{mixfixElement{e.StringElement}, e.start, e.end})
+	}
+	return elems
+}
+
+func (e spannedMixfixElement) Span() (int, int) {
+	return e.start, e.end
+}
+
+func (e spannedMixfixElement) MakeNode(arg1, arg2 *SimpleNode[spannedMixfixElement]) *SimpleNode[spannedMixfixElement] {
+	panic("Expecting MakeSpannedNode to be called instead of MakeNode")
+}
+
+func (e spannedMixfixElement) MakeErrorNode(pe *ParseError[spannedMixfixElement], arg1, arg2 *SimpleNode[spannedMixfixElement]) *SimpleNode[spannedMixfixElement] {
+	panic("Expecting MakeSpannedErrorNode to be called instead of MakeErrorNode")
+}
+
+func (e spannedMixfixElement) MakeMixfixNode(parts []spannedMixfixElement, operands []*SimpleNode[spannedMixfixElement]) *SimpleNode[spannedMixfixElement] {
+	panic("Expecting MakeSpannedMixfixNode to be called instead of MakeMixfixNode")
+}
+
+func (e spannedMixfixElement) MakeSpannedNode(arg1, arg2 *SimpleNode[spannedMixfixElement], start, end int) *SimpleNode[spannedMixfixElement] {
+	return &SimpleNode[spannedMixfixElement]{arg1, arg2, spannedMixfixElement{e.mixfixElement, start, end}}
+}
+
+func (e spannedMixfixElement) MakeSpannedErrorNode(pe *ParseError[spannedMixfixElement], arg1, arg2 *SimpleNode[spannedMixfixElement], start, end int) *SimpleNode[spannedMixfixElement] {
+	se := StringElement(fmt.Sprintf("@error:%v", pe.Kind))
+	return &SimpleNode[spannedMixfixElement]{arg1, arg2, spannedMixfixElement{mixfixElement{se}, start, end}}
+}
+
+func (e spannedMixfixElement) MakeMissingOperand(pe *ParseError[spannedMixfixElement]) *SimpleNode[spannedMixfixElement] {
+	panic("Expecting MakeSpannedMissingOperand to be called instead of MakeMissingOperand")
+}
+
+func (e spannedMixfixElement) MakeSpannedMissingOperand(pe *ParseError[spannedMixfixElement], start, end int) *SimpleNode[spannedMixfixElement] {
+	return &SimpleNode[spannedMixfixElement]{nil, nil, spannedMixfixElement{mixfixElement{"?"}, start, end}}
+}
+
+func (e spannedMixfixElement) MakeSpannedMixfixNode(parts []spannedMixfixElement, operands []*SimpleNode[spannedMixfixElement], start, end int) *SimpleNode[spannedMixfixElement] {
+	var ps, os []string
+	for _, p := range parts {
+		ps = append(ps, string(p.StringElement))
+	}
+	for _, o := range operands {
+		if o.Value.StringElement == "?" {
+			os = append(os, fmt.Sprintf("?@%v", o.Value.start))
+		} else {
+			os = append(os, fmt.Sprintf("%v-%v", o.Value.start, o.Value.end))
+		}
+	}
+	op := testStartsMixfix(string(e.StringElement))
+	return &SimpleNode[spannedMixfixElement]{nil, nil, spannedMixfixElement{mixfixElement{StringElement(showMixfix(op.LeftHole, ps, os))}, start, end}}
+}
+
+func testSpannedMixfix(t *testing.T, input, output, span string, nErrors int) {
+	t.Logf("Input: %v\n", input)
+
+	pool := MakeNodePool[SimpleNode[spannedMixfixElement], spannedMixfixElement](32)
+	root, errs := ParseSlice(makeSpannedMixfixElements(input), pool)
+	if o := ShowSimpleNode(root); o != output {
+		t.Errorf("Expected output: %v\nGot: %v\n", output, o)
+	}
+	if s := input[root.Value.start:root.Value.end]; s != span {
+		t.Errorf("Expected span: %v\nGot: %v\n", span, s)
+	}
+	if len(errs) != nErrors {
+		t.Errorf("Expected %v errors, got %v\n", nErrors, len(errs))
+	}
+}
+
+func TestMixfixSpansAndRecovery(t *testing.T) {
+	// Operands are shown as their spans, and placeholders as '?' followed by
+	// their offset.
+	testSpannedMixfix(t, "c ? a : b", "⦃0-1 ? 4-5 : 8-9⦄", "c ? a : b", 0)
+	testSpannedMixfix(t, "x + if c then a else b", "⎡x + ⦃if 7-8 then 14-15 else 21-22⦄⎦", "x + if c then a else b", 0)
+	testSpannedMixfix(t, "c ? a", "⦃0-1 ? 4-5 _ ?@5⦄", "c ? a", 1)
+	testSpannedMixfix(t, "c ? : b", "⦃0-1 ? ?@3 : 6-7⦄", "c ? : b", 1)
+	testSpannedMixfix(t, "c ? a :", "⦃0-1 ? 4-5 : ?@7⦄", "c ? a :", 1)
+	testSpannedMixfix(t, "if c else a", "⦃if 3-4 _ ?@11 else 10-11⦄", "if c else a", 1)
+	testSpannedMixfix(t, "( c ? a + ) * b", "⎡(⦃2-3 ? 6-9 _ ?@9⦄) * b⎦", "( c ? a + ) * b", 2)
+	testSpannedMixfix(t, "( ( c ? a )$ * b", "⎡((⦃4-5 ? 8-9 _ ?@9⦄)) * b⎦", "( ( c ? a )$ * b", 1)
+}
+
+func TestMixfixTable(t *testing.T) {
+	table := makeTestTable()
+	table.AddOperator("AND", Operator{Kind: InfixOperator, Precedence: 4})
+	for _, op := range testMixfixOperators {
+		if !table.AddMixfixOperator(op) {
+			t.Fatalf("Failed to add mixfix operator %v\n", op.Parts)
+		}
+	}
+	if table.AddMixfixOperator(&MixfixOperator{Parts: []string{"x", ""}}) {
+		t.Errorf("Expected operator with empty part to be rejected\n")
+	}
+	if table.AddMixfixOperator(&MixfixOperator{Parts: []string{"x"}, LeftHole: true, RightHole: true}) {
+		t.Errorf("Expected operator with one part to be rejected\n")
+	}
+	adapter := makeTestAdapter(table)
+	adapter.MakeMixfixNode = func(parts []TokenElement[string, testToken], operands []*string) *string {
+		var ps, os []string
+		for _, p := range parts {
+			ps = append(ps, p.Token.text)
+		}
+		for _, o := range operands {
+			os = append(os, *o)
+		}
+		s := showMixfix(testStartsMixfix(ps[0]).LeftHole, ps, os)
+		return &s
+	}
+
+	testTable(t, adapter, 0, "c ? a : - b", "⦃c ? a : ⎡-b⎦⦄")
+	testTable(t, adapter, 0, "a BETWEEN x AND y AND z", "⎡⦃a BETWEEN x AND y⦄ AND z⎦")
+	testTable(t, adapter, 0, "if c then a else b", "⦃if c then a else b⦄")
+
+	// Mixfix operators registered by ID.
+	table = MakeOperatorTable()
+	ternary := &MixfixOperator{Parts: []string{"?", ":"}, LeftHole: true, RightHole: true}
+	if table.AddMixfixOperatorIDs(ternary, []int{1}) {
+		t.Errorf("Expected wrong number of IDs to be rejected\n")
+	}
+	if table.AddMixfixOperatorIDs(&MixfixOperator{Parts: []string{"x"}}, []int{3}) {
+		t.Errorf("Expected operator with one part to be rejected\n")
+	}
+	table.AddMixfixOperatorIDs(ternary, []int{1, 2})
+	adapter.Table = table
+	pool := MakeNodePool[string, TokenElement[string, testToken]](32)
+	toks := []testToken{{"c", 0}, {"?", 1}, {"a", 0}, {"?", 2}, {"b", 0}}
+	root, errs := ParseSlice(adapter.WrapSlice(toks), pool)
+	if len(errs) != 0 || root == nil || *root != "⦃c ? a ? b⦄" {
+		t.Errorf("Unexpected output %v (%v errors)\n", showStringNode(root), len(errs))
+	}
+}
+
+func TestMixfixTableWithoutMixfixOperators(t *testing.T) {
+	// The adapter has no MakeMixfixNode, which isn't needed if the table has
+	// no mixfix operators.
+	adapter := makeTestAdapter(makeTestTable())
+	pool := MakeNodePool[string, TokenElement[string, testToken]](32)
+	root, errs := ParseSlice(adapter.WrapSlice(makeTestTokens("a + b * c")), pool)
+	if len(errs) != 0 || root == nil || *root != "⎡a + ⎡b * c⎦⎦" {
+		t.Errorf("Unexpected output %v (%v errors)\n", showStringNode(root), len(errs))
+	}
+}
+
+func TestMixfixFuzz(t *testing.T) {
+	// Every input should parse without panicking, and every mixfix operator
+	// should be either complete or reported.
+	tokens := []string{"?", ":", "if", "then", "else", "BETWEEN", "AND", "for", "do", "done"}
+	source := rand.NewSource(12345)
+	rand := rand.New(source)
+	pool := MakeNodePool[SimpleNode[mixfixElement], mixfixElement](64)
+	spannedPool := MakeNodePool[SimpleNode[spannedMixfixElement], spannedMixfixElement](64)
+	for i := 0; i < 10000; i++ {
+		var parts []string
+		for _, se := range randomStringElementSequence(rand) {
+			if rand.Intn(3) == 0 {
+				parts = append(parts, tokens[rand.Intn(len(tokens))])
+			}
+			parts = append(parts, string(se))
+		}
+		input := strings.Join(parts, " ")
+		root, errs := ParseSlice(makeMixfixElements(input), pool)
+		output := ShowSimpleNode(root)
+		if strings.Contains(output, "_") && len(errs) == 0 {
+			t.Errorf("Missing part not reported for input %v: %v\n", input, output)
+		}
+		ParseSlice(makeSpannedMixfixElements(input), spannedPool)
+	}
+}
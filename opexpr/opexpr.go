@@ -2,8 +2,10 @@
 package opexpr
 
 import (
+	"errors"
 	"fmt"
 	"iter"
+	"math"
 	"strings"
 )
 
@@ -96,6 +98,111 @@ type Element interface {
 	Precedence(hasExpressionToLeft bool) int
 }
 
+// Spanned may optionally be implemented by elements that know their location
+// in the source. Span returns the offsets of the start and end of the element
+// (e.g. byte offsets, with end exclusive). The parser does not interpret the
+// offsets except to compare them, so any consistent unit may be used.
+type Spanned interface {
+	Span() (start, end int)
+}
+
+// SpannedTreeBuilder may optionally be implemented by elements that also
+// implement TreeBuilder and Spanned. If it is implemented, its methods are
+// called in place of MakeNode and MakeErrorNode. The start and end arguments
+// give the span of the whole subtree: from the start of its leftmost element to
+// the end of its rightmost element, including any closing parens (which
+// otherwise have no node in the tree).
+type SpannedTreeBuilder[T any, E Element] interface {
+	MakeSpannedNode(leftArg, rightArg *T, start, end int) *T
+	MakeSpannedErrorNode(pe *ParseError[E], leftChild, rightChild *T, start, end int) *T
+}
+
+// MissingOperandBuilder may optionally be implemented by elements that also
+// implement TreeBuilder. If it is implemented, the parser runs in recovery
+// mode: where an operand is missing (as in 'a + * b' or a trailing 'a +'), it
+// inserts a placeholder made by MakeMissingOperand and reports a
+// ParseErrorMissingOperand error, rather than wrapping subtrees in error nodes.
+// Closing parens that are unmatched or of the wrong kind, and missing closing
+// parens, are also reported without error nodes, so the tree has the shape of
+// a correct parse. A ParseErrorMissingClosingParen error is reported for each
+// paren left open at the end of the input. Error nodes are still used where
+// there is an extra element rather than a missing operand: for juxtaposed
+// values when there is no juxtaposition element (as in '1 2'), and for a
+// prefix operator that follows a value (as in '1 ! 2').
+//
+// MakeMissingOperand is called on pe.Elem, which is the element before which
+// the operand is missing, or the last element if the operand is missing at the
+// end of the input.
+type MissingOperandBuilder[T any, E Element] interface {
+	MakeMissingOperand(pe *ParseError[E]) *T
+}
+
+// SpannedMissingOperandBuilder may optionally be implemented by elements that
+// implement MissingOperandBuilder and SpannedTreeBuilder. If it is implemented,
+// MakeSpannedMissingOperand is called in place of MakeMissingOperand. A
+// placeholder has an empty span at the end of the element preceding the
+// missing operand (or at the start of the input). A placeholder for a missing
+// operand of an incomplete mixfix operator has an empty span at the end of the
+// operator's span.
+type SpannedMissingOperandBuilder[T any, E Element] interface {
+	MakeSpannedMissingOperand(pe *ParseError[E], start, end int) *T
+}
+
+// MixfixOperator declares a mixfix operator: a sequence of two or more
+// keyword parts with holes for operands between them, such as C's 'c ? a : b'
+// (parts '?' and ':'), 'if c then a else b' (parts 'if', 'then' and 'else') or
+// SQL's 'a BETWEEN x AND y' (parts 'BETWEEN' and 'AND'). The parser uses only
+// the number of parts; the parts themselves are recognized by
+// MixfixBuilder.StartsMixfix and MixfixBuilder.MixfixPart, which identify the
+// operator by pointer. The holes between parts are delimited (like the inside
+// of a paren). LeftHole and RightHole are true if the operator also has an
+// operand before its first part or after its last part, as in 'c ? a : b'.
+// These open holes bind with the given precedence and associativity, in the
+// same way as the operands of a binary operator. An operator with a single
+// part is an ordinary prefix, postfix or binary operator, and should be
+// declared as such.
+type MixfixOperator struct {
+	Parts      []string
+	LeftHole   bool
+	RightHole  bool
+	Precedence int
+	Assoc      Associativity
+}
+
+// MixfixBuilder may optionally be implemented by elements that also implement
+// TreeBuilder, in order to support mixfix operators.
+type MixfixBuilder[T any, E Element] interface {
+	// StartsMixfix returns the mixfix operator whose first part is the
+	// receiver, or nil. If it returns non-nil, the values of ExpressionKind and
+	// Precedence are not used. The boolean argument is as for ExpressionKind.
+	// An operator with fewer than two parts is ignored.
+	StartsMixfix(hasExpressionToLeft bool) *MixfixOperator
+	// MixfixPart returns the index of the receiver among the parts of op, or -1
+	// if it is not a part of op (an out of range index is treated as -1). It is
+	// called when the innermost hole is a delimited hole of op, and takes
+	// precedence over StartsMixfix (so that, for example, 'AND' can end
+	// 'BETWEEN ... AND' but otherwise be an infix operator).
+	MixfixPart(op *MixfixOperator) int
+	// MakeMixfixNode is called on the first part of a mixfix operator to make
+	// its parse tree node. parts has an element for each part of the operator,
+	// and operands has an element for each hole (including LeftHole and
+	// RightHole, if present). If parts are missing, the corresponding elements
+	// of parts are zero values, and the corresponding elements of operands are
+	// nil (or placeholders made by MakeMissingOperand in recovery mode). The
+	// slices are reused after the call returns.
+	MakeMixfixNode(parts []E, operands []*T) *T
+}
+
+// SpannedMixfixBuilder may optionally be implemented by elements that
+// implement MixfixBuilder and SpannedTreeBuilder. If it is implemented,
+// MakeSpannedMixfixNode is called in place of MakeMixfixNode.
+type SpannedMixfixBuilder[T any, E Element] interface {
+	MakeSpannedMixfixNode(parts []E, operands []*T, start, end int) *T
+}
+
+// The paren kind recorded for the delimited holes of mixfix operators.
+const mixfixParenKind = math.MinInt
+
 type ParseErrorKind int
 
 const (
@@ -104,6 +211,9 @@ const (
 	ParseErrorUnexpectedClosingParen                        // closing parent found with no matching opening paren
 	ParseErrorWrongKindOfClosingParen                       // opening paren closed with wrong kind of closing paren (e.g. '(' is closed with ']')
 	ParseErrorMissingClosingParen                           // missing closing paren
+	ParseErrorMissingOperand                                // operand missing before or after an element (reported only in recovery mode)
+	ParseErrorWrongMixfixPart                               // part of a mixfix operator found in place of a different part (e.g. 'if c else a')
+	ParseErrorMissingMixfixPart                             // mixfix operator not completed before a closing paren or the end of the input
 )
 
 func (k ParseErrorKind) String() string {
@@ -118,22 +228,66 @@ func (k ParseErrorKind) String() string {
 		return "ParseErrorWrongKindOfClosingParen"
 	case ParseErrorMissingClosingParen:
 		return "ParseErrorMissingClosingParen"
+	case ParseErrorMissingOperand:
+		return "ParseErrorMissingOperand"
+	case ParseErrorWrongMixfixPart:
+		return "ParseErrorWrongMixfixPart"
+	case ParseErrorMissingMixfixPart:
+		return "ParseErrorMissingMixfixPart"
 	default:
 		panic("Unrecognized ParseErrorKind")
 	}
 }
 
-// ParseError represents a parse error. Elem is either to a pointer to an
-// element or nil if Kind is ParseErrorMissingClosingParen.
+// ParseError represents a parse error. Elem is the offending element, and
+// Index is its index in the input. If Kind is ParseErrorMissingClosingParen,
+// Elem is the last element of the input. If Kind is ParseErrorMissingOperand,
+// Elem is the element before which the operand is missing, or the last element
+// of the input. For ParseErrorMissingClosingParen and
+// ParseErrorWrongKindOfClosingParen, Related is the opening paren that was not
+// properly closed, and RelatedIndex is its index in the input. For
+// ParseErrorWrongMixfixPart and ParseErrorMissingMixfixPart, Related is the
+// first part of the mixfix operator (and for ParseErrorMissingMixfixPart, Elem
+// is the closing paren or the last element of the input). Otherwise
+// RelatedIndex is -1.
 type ParseError[EP Element] struct {
-	Kind ParseErrorKind
-	Elem EP
+	Kind         ParseErrorKind
+	Elem         EP
+	Index        int
+	Related      EP
+	RelatedIndex int
+}
+
+func newParseError[EP Element](kind ParseErrorKind, elem EP, index int) *ParseError[EP] {
+	return &ParseError[EP]{Kind: kind, Elem: elem, Index: index, RelatedIndex: -1}
 }
 
 func ShowParseError[EP Element](pe *ParseError[EP]) string {
 	return fmt.Sprintf("%v@%v", pe.Kind, pe.Elem)
 }
 
+// Error implements the error interface.
+func (pe *ParseError[EP]) Error() string {
+	if pe.RelatedIndex == -1 {
+		return fmt.Sprintf("%v at element %v (%v)", pe.Kind, pe.Index, pe.Elem)
+	}
+	return fmt.Sprintf("%v at element %v (%v) for element %v (%v)", pe.Kind, pe.Index, pe.Elem, pe.RelatedIndex, pe.Related)
+}
+
+// JoinParseErrors returns an error that wraps each of the errors returned by
+// ParseSlice (or any of the other parse functions), or nil if there are no
+// errors. The individual errors can be retrieved using errors.As.
+func JoinParseErrors[EP Element](errs []*ParseError[EP]) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	wrapped := make([]error, len(errs))
+	for i, pe := range errs {
+		wrapped[i] = pe
+	}
+	return errors.Join(wrapped...)
+}
+
 // TreeBuilder defines methods for building a tree out of input elements
 type TreeBuilder[T any, E Element] interface {
 	Element
@@ -156,16 +310,21 @@ type SimpleNode[T Element] struct {
 }
 
 type nodePool[T any, E TreeBuilder[T, E]] struct {
-	nodes      []node[T, E]
-	parenRoots []**node[T, E]
-	parenKinds []int
-	stack      []*node[T, E]
+	nodes        []node[T, E]
+	parenRoots   []**node[T, E]
+	parenKinds   []int
+	parenOpeners []*node[T, E]
+	stack        []*node[T, E]
+
+	// Buffers for the arguments of MakeMixfixNode.
+	mixfixParts    []E
+	mixfixOperands []*T
 }
 
 // MakeNodePool makes a pool of shadow parse tree nodes for a given tree node
 // type and element type.
 func MakeNodePool[T any, E TreeBuilder[T, E]](capacity int) *nodePool[T, E] {
-	return &nodePool[T, E]{make([]node[T, E], capacity), make([]**node[T, E], capacity/4), make([]int, capacity/4), make([]*node[T, E], capacity/4)}
+	return &nodePool[T, E]{make([]node[T, E], capacity), make([]**node[T, E], capacity/4), make([]int, capacity/4), make([]*node[T, E], capacity/4), make([]*node[T, E], capacity/4), nil, nil}
 }
 
 type node[T any, EP Element] struct {
@@ -177,14 +336,61 @@ type node[T any, EP Element] struct {
 	// This field is used to memoize traversal of the tree when finding the
 	// appropriate level to insert a right-associative operator.
 	bottom *node[T, EP]
+
+	// The hasExpressionToLeft argument that determined the kind and precedence
+	// of elem.
+	exprToLeft bool
+	// The index of elem in the input, or -1 if elem is not an input element
+	// (e.g. for a juxtaposition node).
+	index int
+	// For a node that opens a paren level, the index of the closing paren in
+	// the input, or -1 if the level was not closed.
+	closeIndex int
+	// The span of the subtree rooted at this node (only computed for Spanned
+	// elements).
+	start, end int
+	// Whether this is a placeholder for a missing operand (in recovery mode),
+	// and if so whether the operand is missing before or after err.Elem.
+	missing missingKind
+
+	// For the node of a mixfix operator (whose elem is its first part), the
+	// operator, the index of the next part expected, and the first of the
+	// nodes holding its delimited operands (which are not otherwise part of the
+	// tree). mixErr is the first error (if any) concerning its parts.
+	mixfix   *MixfixOperator
+	nextPart int
+	slots    *node[T, EP]
+	mixErr   *ParseError[EP]
+	// For a node holding a delimited operand of a mixfix operator (in left),
+	// the index of the preceding part and the part that closed the hole (whose
+	// index in the input is closeIndex). slots is the next such node.
+	part, closePart int
 }
 
+type missingKind int
+
+const (
+	notMissing missingKind = iota
+	missingBefore
+	missingAfter
+)
+
 func zeroNode[T any, EP Element](n *node[T, EP]) {
 	n.left = nil
 	n.right = nil
 	n.treeNode = nil
 	n.err = nil
 	n.bottom = nil
+	n.index = -1
+	n.closeIndex = -1
+	n.missing = notMissing
+	n.exprToLeft = false
+	n.mixfix = nil
+	n.nextPart = 0
+	n.slots = nil
+	n.mixErr = nil
+	n.part = 0
+	n.closePart = 0
 }
 
 // ParseSeq parses an sequence of input elements. It returns pointer to the root
@@ -230,43 +436,277 @@ func ParseSlice[T any, E TreeBuilder[T, E]](elements []E, pool *nodePool[T, E])
 func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxtapositionElement *E, pool *nodePool[T, E]) (*T, []*ParseError[E]) {
 	var errors []*ParseError[E]
 
+	// Spans are computed only if the elements support them.
+	var zero E
+	_, spanned := any(zero).(Spanned)
+	_, spanBuilder := any(zero).(SpannedTreeBuilder[T, E])
+	spanned = spanned && spanBuilder
+	// Recovery mode is used only if the elements support it.
+	_, recovering := any(zero).(MissingOperandBuilder[T, E])
+	// Mixfix operators are recognized only if the elements support them.
+	_, mixfixes := any(zero).(MixfixBuilder[T, E])
+
 	// Shortcut for the very common case of a single expression
 	if len(elements) == 1 {
 		e := elements[0]
-		if e.ExpressionKind(false) == Value {
+		if e.ExpressionKind(false) == Value && !(mixfixes && startsMixfix(any(e).(MixfixBuilder[T, E]), false) != nil) {
+			if spanned {
+				start, end := elemAs[Spanned](&elements[0]).Span()
+				return elemAs[SpannedTreeBuilder[T, E]](&elements[0]).MakeSpannedNode(nil, nil, start, end), errors
+			}
 			return e.MakeNode(nil, nil), errors
 		}
 	}
 
 	poolSize := len(elements)*2 + 1
+	if mixfixes {
+		// The start of a mixfix operator may use three nodes.
+		poolSize = len(elements)*3 + 2
+	}
 	if len(pool.nodes) < poolSize {
 		pool.nodes = append(pool.nodes, make([]node[T, E], poolSize-len(pool.nodes))...)
 	}
 	poolI := 0
 
+	// In recovery mode, fills a hole with a placeholder for an operand that is
+	// missing before or after the element at index i.
+	fillHole := func(hole **node[T, E], i int, missing missingKind) {
+		pe := newParseError(ParseErrorMissingOperand, elements[i], i)
+		errors = append(errors, pe)
+		placeholder := &pool.nodes[poolI]
+		poolI++
+		zeroNode(placeholder)
+		placeholder.elem = elements[i]
+		placeholder.err = pe
+		placeholder.missing = missing
+		*hole = placeholder
+	}
+
 	var root *node[T, E]
 	hole := &root
 
 	// for each paren level.
 	pool.parenRoots = pool.parenRoots[:1] // reset to length 1 while leaving existing capacity
 	pool.parenKinds = pool.parenKinds[:0] // reset to empty while leaving existing capacity
+	pool.parenOpeners = pool.parenOpeners[:0]
 	pool.parenRoots[0] = &root
 
 	// used later to alloc an approtiately-sized stack for traversing the
 	// temporary parse tree.
 	depth := 1
 
+	// Handles the element at index i, which needs an operand to its left,
+	// given that there is none (i.e. hole != nil).
+	missingLeftOperand := func(hole **node[T, E], i int) {
+		if recovering {
+			fillHole(hole, i, missingBefore)
+		} else {
+			pe := newParseError(ParseErrorUnexpectedOperator, elements[i], i)
+			errors = append(errors, pe)
+			errorNode := &pool.nodes[poolI]
+			poolI++
+			zeroNode(errorNode)
+			errorNode.elem = elements[i]
+			errorNode.index = i
+			errorNode.err = pe
+			*hole = errorNode
+		}
+	}
+
+	// Handles the element at index i, which can't take an operand to its left,
+	// given that there is one (i.e. hole == nil). The juxtaposition element is
+	// used if there is one. Otherwise, an error of the given kind is reported.
+	// It returns the new hole.
+	unexpectedLeftOperand := func(i int, kind ParseErrorKind, parenRootP **node[T, E]) **node[T, E] {
+		if juxtapositionElement == nil {
+			pe := newParseError(kind, elements[i], i)
+			errors = append(errors, pe)
+			errorNode := &pool.nodes[poolI]
+			poolI++
+			zeroNode(errorNode)
+			errorNode.elem = elements[i]
+			errorNode.index = i
+			errorNode.left = *parenRootP
+			errorNode.err = pe
+			*parenRootP = errorNode
+			return &errorNode.right
+		}
+		jux := *juxtapositionElement
+		n := findOpLevel(jux.Precedence(true), jux.ExpressionKind(true)&isRightAssoc != 0, parenRootP)
+		opNode := &pool.nodes[poolI]
+		poolI++
+		zeroNode(opNode)
+		opNode.elem = jux
+		opNode.exprToLeft = true
+		opNode.left = *n
+		*n = opNode
+		return &opNode.right
+	}
+
+	// Opens the delimited hole following the given part of the mixfix
+	// operator at h, and returns it.
+	openMixfixHole := func(h *node[T, E], part int) **node[T, E] {
+		slot := &pool.nodes[poolI]
+		poolI++
+		zeroNode(slot)
+		slot.part = part
+		if h.slots == nil {
+			h.slots = slot
+		} else {
+			lastMixfixSlot(h).slots = slot
+		}
+		pool.parenRoots = append(pool.parenRoots, &slot.left)
+		pool.parenKinds = append(pool.parenKinds, mixfixParenKind)
+		pool.parenOpeners = append(pool.parenOpeners, h)
+		return &slot.left
+	}
+
+	// Reports that the mixfix operator at h is missing parts, given that the
+	// element at index i can't continue it.
+	abandonMixfix := func(h *node[T, E], i int) {
+		pe := newParseError(ParseErrorMissingMixfixPart, elements[i], i)
+		pe.Related, pe.RelatedIndex = h.elem, h.index
+		errors = append(errors, pe)
+		if h.mixErr == nil {
+			h.mixErr = pe
+		}
+	}
+
 	for i := range elements {
 		e := elements[i]
 
-		ekind := e.ExpressionKind(hole == nil)
+		exprToLeft := hole == nil
+		ekind := e.ExpressionKind(exprToLeft)
 
 		parenRootP := pool.parenRoots[len(pool.parenRoots)-1]
 
-		if ekind&isCloseParen != 0 {
+		if mixfixes {
+			mb := any(e).(MixfixBuilder[T, E])
+
+			if h := innermostMixfix(pool); h != nil {
+				if part := mb.MixfixPart(h.mixfix); part > 0 && part < len(h.mixfix.Parts) {
+					if part != h.nextPart {
+						pe := newParseError(ParseErrorWrongMixfixPart, e, i)
+						pe.Related, pe.RelatedIndex = h.elem, h.index
+						errors = append(errors, pe)
+						if h.mixErr == nil {
+							h.mixErr = pe
+						}
+						if part < h.nextPart {
+							// A repeated part is ignored, and a later part skips
+							// the parts in between.
+							continue
+						}
+					}
+					if hole != nil {
+						missingLeftOperand(hole, i)
+						hole = nil
+						depth++
+					}
+					slot := lastMixfixSlot(h)
+					slot.closeIndex = i
+					slot.closePart = part
+					pool.parenRoots = pool.parenRoots[:len(pool.parenRoots)-1]
+					pool.parenKinds = pool.parenKinds[:len(pool.parenKinds)-1]
+					pool.parenOpeners = pool.parenOpeners[:len(pool.parenOpeners)-1]
+					h.nextPart = part + 1
+					if h.nextPart < len(h.mixfix.Parts) {
+						hole = openMixfixHole(h, part)
+						depth++
+					} else if h.mixfix.RightHole {
+						hole = &h.right
+					} else {
+						hole = nil
+					}
+					continue
+				}
+			}
+
+			if op := startsMixfix(mb, exprToLeft); op != nil {
+				h := &pool.nodes[poolI]
+				poolI++
+				zeroNode(h)
+				h.elem = e
+				h.index = i
+				h.mixfix = op
+				h.nextPart = 1
+				if op.LeftHole {
+					if hole != nil {
+						missingLeftOperand(hole, i)
+						hole = nil
+						depth++
+					}
+					n := findOpLevel(op.Precedence, op.Assoc == RightAssoc, parenRootP)
+					h.left = *n
+					*n = h
+				} else {
+					if hole == nil {
+						hole = unexpectedLeftOperand(i, ParseErrorUnexpectedOperator, parenRootP)
+						depth++
+					}
+					*hole = h
+				}
+				depth++
+				hole = openMixfixHole(h, 0)
+				depth++
+				continue
+			}
+
+			if ekind&isCloseParen != 0 {
+				// A closing paren can't close the delimited hole of a mixfix
+				// operator, so the innermost mixfix operators are incomplete.
+				for h := innermostMixfix(pool); h != nil; h = innermostMixfix(pool) {
+					abandonMixfix(h, i)
+					if hole != nil && recovering && hole != &lastMixfixSlot(h).left {
+						fillHole(hole, i, missingBefore)
+						depth++
+					}
+					hole = nil
+					pool.parenRoots = pool.parenRoots[:len(pool.parenRoots)-1]
+					pool.parenKinds = pool.parenKinds[:len(pool.parenKinds)-1]
+					pool.parenOpeners = pool.parenOpeners[:len(pool.parenOpeners)-1]
+				}
+				parenRootP = pool.parenRoots[len(pool.parenRoots)-1]
+			}
+		}
+
+		if ekind&isCloseParen != 0 && recovering {
+			// closing parens (in recovery mode)
+			if len(pool.parenRoots) <= 1 {
+				errors = append(errors, newParseError(ParseErrorUnexpectedClosingParen, e, i))
+				continue
+			}
+			opener := pool.parenOpeners[len(pool.parenOpeners)-1]
+			if e.ParenKind() != pool.parenKinds[len(pool.parenKinds)-1] {
+				pe := newParseError(ParseErrorWrongKindOfClosingParen, e, i)
+				pe.Related, pe.RelatedIndex = opener.elem, opener.index
+				errors = append(errors, pe)
+			}
+			if hole != nil {
+				fillHole(hole, i, missingBefore)
+				depth++
+			}
+			hole = nil
+			if ekind&isCloseAllParen != 0 {
+				for _, opener := range pool.parenOpeners {
+					opener.closeIndex = i
+					if opener.mixfix != nil {
+						abandonMixfix(opener, i)
+					}
+				}
+				pool.parenRoots = pool.parenRoots[0:1]
+				pool.parenKinds = pool.parenKinds[0:0]
+				pool.parenOpeners = pool.parenOpeners[0:0]
+			} else {
+				opener.closeIndex = i
+				pool.parenRoots = pool.parenRoots[:len(pool.parenRoots)-1]
+				pool.parenKinds = pool.parenKinds[:len(pool.parenKinds)-1]
+				pool.parenOpeners = pool.parenOpeners[:len(pool.parenOpeners)-1]
+			}
+		} else if ekind&isCloseParen != 0 {
 			// closing parens
 			if len(pool.parenRoots) <= 1 {
-				pe := &ParseError[E]{ParseErrorUnexpectedClosingParen, e}
+				pe := newParseError(ParseErrorUnexpectedClosingParen, e, i)
 				errors = append(errors, pe)
 				node := &pool.nodes[poolI]
 				poolI++
@@ -275,7 +715,9 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 				node.err = pe
 				root = node
 			} else if e.ParenKind() != pool.parenKinds[len(pool.parenKinds)-1] {
-				pe := &ParseError[E]{ParseErrorWrongKindOfClosingParen, e}
+				pe := newParseError(ParseErrorWrongKindOfClosingParen, e, i)
+				opener := pool.parenOpeners[len(pool.parenOpeners)-1]
+				pe.Related, pe.RelatedIndex = opener.elem, opener.index
 				errors = append(errors, pe)
 				node := &pool.nodes[poolI]
 				poolI++
@@ -285,34 +727,38 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 				node.err = pe
 				*rt = node
 			} else if ekind&isCloseAllParen != 0 {
+				for _, opener := range pool.parenOpeners {
+					opener.closeIndex = i
+					if opener.mixfix != nil {
+						abandonMixfix(opener, i)
+					}
+				}
 				pool.parenRoots = pool.parenRoots[0:1]
 				pool.parenKinds = pool.parenKinds[0:0]
+				pool.parenOpeners = pool.parenOpeners[0:0]
 			} else {
+				pool.parenOpeners[len(pool.parenOpeners)-1].closeIndex = i
 				pool.parenRoots = pool.parenRoots[:len(pool.parenRoots)-1]
 				pool.parenKinds = pool.parenKinds[:len(pool.parenKinds)-1]
+				pool.parenOpeners = pool.parenOpeners[:len(pool.parenOpeners)-1]
 			}
 		} else if ekind&hasLeftArg != 0 {
 			// postfix op or bin op
 
 			if hole != nil {
-				pe := &ParseError[E]{ParseErrorUnexpectedOperator, e}
-				errors = append(errors, pe)
-				errorNode := &pool.nodes[poolI]
-				poolI++
-				zeroNode(errorNode)
-				errorNode.elem = e
-				errorNode.err = pe
-				*hole = errorNode
+				missingLeftOperand(hole, i)
 				hole = nil
 				depth++
 			}
 
-			n := findOpLevel(e, parenRootP, hole)
+			n := findOpLevel(e.Precedence(true), e.ExpressionKind(true)&isRightAssoc != 0, parenRootP)
 
 			opNode := &pool.nodes[poolI]
 			poolI++
 			zeroNode(opNode)
 			opNode.elem = e
+			opNode.index = i
+			opNode.exprToLeft = true
 			opNode.left = *n
 
 			if ekind&hasRightArg != 0 {
@@ -322,6 +768,7 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 					depth++
 					pool.parenRoots = append(pool.parenRoots, &opNode.right)
 					pool.parenKinds = append(pool.parenKinds, e.ParenKind())
+					pool.parenOpeners = append(pool.parenOpeners, opNode)
 				}
 				hole = &opNode.right
 			}
@@ -332,27 +779,7 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 			// prefix op
 
 			if hole == nil {
-				if juxtapositionElement == nil {
-					pe := &ParseError[E]{ParseErrorUnexpectedOperator, e}
-					errors = append(errors, pe)
-					errorNode := &pool.nodes[poolI]
-					poolI++
-					zeroNode(errorNode)
-					errorNode.elem = e
-					errorNode.left = *parenRootP
-					errorNode.err = pe
-					hole = &errorNode.right
-					*parenRootP = errorNode
-				} else {
-					n := findOpLevel(*juxtapositionElement, parenRootP, hole)
-					opNode := &pool.nodes[poolI]
-					poolI++
-					zeroNode(opNode)
-					opNode.elem = *juxtapositionElement
-					opNode.left = *n
-					hole = &opNode.right
-					*n = opNode
-				}
+				hole = unexpectedLeftOperand(i, ParseErrorUnexpectedOperator, parenRootP)
 				depth++
 			}
 
@@ -360,6 +787,8 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 			poolI++
 			zeroNode(opNode)
 			opNode.elem = e
+			opNode.index = i
+			opNode.exprToLeft = exprToLeft
 			*hole = opNode
 			hole = &opNode.right
 			depth++
@@ -370,29 +799,10 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 			poolI++
 			zeroNode(valueNode)
 			valueNode.elem = e
+			valueNode.index = i
 
 			if hole == nil {
-				if juxtapositionElement == nil {
-					pe := &ParseError[E]{ParseErrorUnexpectedValue, e}
-					errors = append(errors, pe)
-					errorNode := &pool.nodes[poolI]
-					poolI++
-					zeroNode(errorNode)
-					errorNode.elem = e
-					errorNode.left = *parenRootP
-					errorNode.err = pe
-					hole = &errorNode.right
-					*parenRootP = errorNode
-				} else {
-					n := findOpLevel(*juxtapositionElement, parenRootP, hole)
-					opNode := &pool.nodes[poolI]
-					poolI++
-					zeroNode(opNode)
-					opNode.elem = *juxtapositionElement
-					opNode.left = *n
-					hole = &opNode.right
-					*n = opNode
-				}
+				hole = unexpectedLeftOperand(i, ParseErrorUnexpectedValue, parenRootP)
 				depth++
 			}
 
@@ -403,6 +813,7 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 				depth++
 				pool.parenRoots = append(pool.parenRoots, &valueNode.right)
 				pool.parenKinds = append(pool.parenKinds, e.ParenKind())
+				pool.parenOpeners = append(pool.parenOpeners, valueNode)
 				hole = &valueNode.right
 			} else {
 				hole = nil
@@ -410,23 +821,54 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 		}
 	}
 
-	if hole != nil && len(elements) > 0 {
-		pe := &ParseError[E]{ParseErrorUnexpectedOperator, elements[len(elements)-1]}
+	if recovering {
+		last := len(elements) - 1
+		if hole != nil && last >= 0 {
+			fillHole(hole, last, missingAfter)
+		}
+		for i := len(pool.parenOpeners) - 1; i >= 0; i-- {
+			if pool.parenOpeners[i].mixfix != nil {
+				abandonMixfix(pool.parenOpeners[i], last)
+				continue
+			}
+			pe := newParseError(ParseErrorMissingClosingParen, elements[last], last)
+			pe.Related, pe.RelatedIndex = pool.parenOpeners[i].elem, pool.parenOpeners[i].index
+			errors = append(errors, pe)
+		}
+	} else if hole != nil && len(elements) > 0 {
+		pe := newParseError(ParseErrorUnexpectedOperator, elements[len(elements)-1], len(elements)-1)
 		errors = append(errors, pe)
 		errorNode := &pool.nodes[poolI]
 		poolI++
 		zeroNode(errorNode)
 		errorNode.elem = elements[len(elements)-1]
+		errorNode.index = len(elements) - 1
 		errorNode.err = pe
 		*hole = errorNode
 	}
 
+	// Report incomplete mixfix operators, and find the innermost paren level
+	// that is still open.
+	innermostParen := len(pool.parenOpeners) - 1
+	if mixfixes && !recovering {
+		innermostParen = -1
+		for i := len(pool.parenOpeners) - 1; i >= 0; i-- {
+			if pool.parenOpeners[i].mixfix != nil {
+				abandonMixfix(pool.parenOpeners[i], len(elements)-1)
+			} else if innermostParen == -1 {
+				innermostParen = i
+			}
+		}
+	}
+
 	// Wrap with an error node if there are missing closing parens and we don't
 	// already have a 'wrong kind' error.
-	if len(pool.parenRoots) > 1 {
-		last := pool.parenRoots[len(pool.parenRoots)-1]
+	if innermostParen != -1 && !recovering {
+		last := pool.parenRoots[innermostParen+1]
 		if !(last != nil && (*last).err != nil && (*last).err.Kind == ParseErrorWrongKindOfClosingParen) {
-			pe := &ParseError[E]{ParseErrorMissingClosingParen, elements[len(elements)-1]}
+			pe := newParseError(ParseErrorMissingClosingParen, elements[len(elements)-1], len(elements)-1)
+			opener := pool.parenOpeners[innermostParen]
+			pe.Related, pe.RelatedIndex = opener.elem, opener.index
 			errors = append(errors, pe)
 			node := &pool.nodes[poolI]
 			// no need to increment poolI as we won't be using the pool again
@@ -437,26 +879,25 @@ func ParseSliceWithJuxtaposition[T any, E TreeBuilder[T, E]](elements []E, juxta
 		}
 	}
 
-	rr, errs := buildTree(root, depth, pool), errors
+	rr, errs := buildTree(root, depth, pool, elements, spanned, recovering), errors
 
 	return rr, errs
 }
 
-func findOpLevel[T any, E TreeBuilder[T, E]](e E, root **node[T, E], hole **node[T, E]) **node[T, E] {
-	exprToLeft := hole == nil
-	precedenceCmp := e.Precedence(exprToLeft)
+func findOpLevel[T any, E TreeBuilder[T, E]](precedence int, rightAssoc bool, root **node[T, E]) **node[T, E] {
+	precedenceCmp := precedence
 	n := root
 
-	if e.ExpressionKind(exprToLeft)&isRightAssoc == 0 {
+	if !rightAssoc {
 		precedenceCmp++
 	}
 
-	prec := (*n).elem.Precedence(exprToLeft)
+	prec := precedenceOf(*n)
 	for {
 		if *n == nil || (*n).err != nil {
 			return n
 		}
-		ek := (*n).elem.ExpressionKind(exprToLeft)
+		ek := expressionKindOf(*n)
 		if ek&hasRightArg == 0 {
 			break
 		}
@@ -471,11 +912,11 @@ func findOpLevel[T any, E TreeBuilder[T, E]](e E, root **node[T, E], hole **node
 
 		if (*n).bottom != nil {
 			n = &((*n).bottom.right)
-			prec = (*n).elem.Precedence(exprToLeft)
+			prec = precedenceOf(*n)
 		} else {
 			oldn := *n
 			n = &((*n).right)
-			newprec := (*n).elem.Precedence(exprToLeft)
+			newprec := precedenceOf(*n)
 			if prec == newprec {
 				(*root).bottom = oldn
 			}
@@ -486,7 +927,67 @@ func findOpLevel[T any, E TreeBuilder[T, E]](e E, root **node[T, E], hole **node
 	return n
 }
 
-func buildTree[T any, E TreeBuilder[T, E]](root *node[T, E], stackDepth int, pool *nodePool[T, E]) *T {
+// expressionKindOf returns the kind of the operator at n, as determined when n
+// was added to the tree. A complete mixfix operator with a RightHole behaves as
+// a prefix operator, and any other mixfix operator as a value.
+func expressionKindOf[T any, E Element](n *node[T, E]) ExpressionKind {
+	if n.mixfix != nil {
+		if n.nextPart == len(n.mixfix.Parts) && n.mixfix.RightHole {
+			if n.mixfix.Assoc == RightAssoc {
+				return Prefix | isRightAssoc
+			}
+			return Prefix
+		}
+		return Value
+	}
+	return n.elem.ExpressionKind(n.exprToLeft)
+}
+
+func precedenceOf[T any, E Element](n *node[T, E]) int {
+	if n.mixfix != nil {
+		return n.mixfix.Precedence
+	}
+	return n.elem.Precedence(n.exprToLeft)
+}
+
+// innermostMixfix returns the node of the mixfix operator whose delimited hole
+// is the innermost paren level, or nil.
+func innermostMixfix[T any, E TreeBuilder[T, E]](pool *nodePool[T, E]) *node[T, E] {
+	if len(pool.parenOpeners) == 0 || pool.parenOpeners[len(pool.parenOpeners)-1].mixfix == nil {
+		return nil
+	}
+	return pool.parenOpeners[len(pool.parenOpeners)-1]
+}
+
+// startsMixfix returns the mixfix operator started by mb, or nil if there is
+// none or it has fewer than two parts.
+func startsMixfix[T any, E Element](mb MixfixBuilder[T, E], hasExpressionToLeft bool) *MixfixOperator {
+	if op := mb.StartsMixfix(hasExpressionToLeft); op != nil && len(op.Parts) >= 2 {
+		return op
+	}
+	return nil
+}
+
+func lastMixfixSlot[T any, E Element](h *node[T, E]) *node[T, E] {
+	slot := h.slots
+	for slot.slots != nil {
+		slot = slot.slots
+	}
+	return slot
+}
+
+// nextUnbuiltSlot returns the first node holding a delimited operand of the
+// mixfix operator at n whose tree node has not yet been built, or nil.
+func nextUnbuiltSlot[T any, E Element](n *node[T, E]) *node[T, E] {
+	for slot := n.slots; slot != nil; slot = slot.slots {
+		if slot.left != nil && slot.left.treeNode == nil {
+			return slot
+		}
+	}
+	return nil
+}
+
+func buildTree[T any, E TreeBuilder[T, E]](root *node[T, E], stackDepth int, pool *nodePool[T, E], elements []E, spanned, recovering bool) *T {
 	if root == nil {
 		return nil
 	}
@@ -502,6 +1003,9 @@ func buildTree[T any, E TreeBuilder[T, E]](root *node[T, E], stackDepth int, poo
 			if current.left != nil && current.left.treeNode == nil {
 				pool.stack = append(pool.stack, current)
 				current = current.left
+			} else if slot := nextUnbuiltSlot(current); slot != nil {
+				pool.stack = append(pool.stack, current)
+				current = slot.left
 			} else if current.right != nil && current.right.treeNode == nil {
 				pool.stack = append(pool.stack, current)
 				current = current.right
@@ -511,7 +1015,28 @@ func buildTree[T any, E TreeBuilder[T, E]](root *node[T, E], stackDepth int, poo
 		}
 
 		ce := current.elem
-		if current.err == nil {
+		if current.mixfix != nil {
+			buildMixfixNode(current, pool, elements, spanned, recovering)
+		} else if current.missing != notMissing {
+			if spanned {
+				setSpan(current, elements)
+			}
+			if sb, ok := any(ce).(SpannedMissingOperandBuilder[T, E]); ok && spanned {
+				current.treeNode = sb.MakeSpannedMissingOperand(current.err, current.start, current.end)
+			} else {
+				current.treeNode = any(ce).(MissingOperandBuilder[T, E]).MakeMissingOperand(current.err)
+			}
+			current.err = nil
+		} else if spanned {
+			setSpan(current, elements)
+			sb := elemAs[SpannedTreeBuilder[T, E]](&current.elem)
+			if current.err == nil {
+				current.treeNode = sb.MakeSpannedNode(leftTreeNodeOf(current), rightTreeNodeOf(current), current.start, current.end)
+			} else {
+				current.treeNode = sb.MakeSpannedErrorNode(current.err, leftTreeNodeOf(current), rightTreeNodeOf(current), current.start, current.end)
+				current.err = nil
+			}
+		} else if current.err == nil {
 			current.treeNode = ce.MakeNode(leftTreeNodeOf(current), rightTreeNodeOf(current))
 		} else {
 			current.treeNode = ce.MakeErrorNode(current.err, leftTreeNodeOf(current), rightTreeNodeOf(current))
@@ -528,6 +1053,144 @@ func buildTree[T any, E TreeBuilder[T, E]](root *node[T, E], stackDepth int, poo
 	return root.treeNode
 }
 
+// buildMixfixNode builds the tree node for the mixfix operator at h, given that
+// the tree nodes for its operands have already been built.
+func buildMixfixNode[T any, E TreeBuilder[T, E]](h *node[T, E], pool *nodePool[T, E], elements []E, spanned, recovering bool) {
+	op := h.mixfix
+	var zero E
+
+	parts := append(pool.mixfixParts[:0], h.elem)
+	for len(parts) < len(op.Parts) {
+		parts = append(parts, zero)
+	}
+
+	nOperands := len(op.Parts) - 1
+	first := 0
+	if op.LeftHole {
+		nOperands++
+		first = 1
+	}
+	if op.RightHole {
+		nOperands++
+	}
+	operands := pool.mixfixOperands[:0]
+	for range nOperands {
+		operands = append(operands, nil)
+	}
+
+	if op.LeftHole && h.left != nil {
+		operands[0] = h.left.treeNode
+	}
+	for slot := h.slots; slot != nil; slot = slot.slots {
+		if slot.left != nil {
+			operands[first+slot.part] = slot.left.treeNode
+		}
+		if slot.closeIndex != -1 {
+			parts[slot.closePart] = elements[slot.closeIndex]
+		}
+	}
+	if op.RightHole && h.right != nil {
+		operands[nOperands-1] = h.right.treeNode
+	}
+	if spanned {
+		setSpan(h, elements)
+	}
+	if recovering && h.mixErr != nil {
+		sb, spannedMissing := any(h.mixErr.Elem).(SpannedMissingOperandBuilder[T, E])
+		for i := range operands {
+			if operands[i] != nil {
+				continue
+			}
+			if spanned && spannedMissing {
+				operands[i] = sb.MakeSpannedMissingOperand(h.mixErr, h.end, h.end)
+			} else {
+				operands[i] = any(h.mixErr.Elem).(MissingOperandBuilder[T, E]).MakeMissingOperand(h.mixErr)
+			}
+		}
+	}
+	pool.mixfixParts, pool.mixfixOperands = parts, operands
+
+	if spanned {
+		if sb, ok := any(h.elem).(SpannedMixfixBuilder[T, E]); ok {
+			h.treeNode = sb.MakeSpannedMixfixNode(parts, operands, h.start, h.end)
+		} else {
+			h.treeNode = any(h.elem).(MixfixBuilder[T, E]).MakeMixfixNode(parts, operands)
+		}
+	} else {
+		h.treeNode = any(h.elem).(MixfixBuilder[T, E]).MakeMixfixNode(parts, operands)
+	}
+
+	if h.mixErr != nil && !recovering {
+		if spanned {
+			h.treeNode = elemAs[SpannedTreeBuilder[T, E]](&h.elem).MakeSpannedErrorNode(h.mixErr, h.treeNode, nil, h.start, h.end)
+		} else {
+			h.treeNode = h.elem.MakeErrorNode(h.mixErr, h.treeNode, nil)
+		}
+	}
+}
+
+// setSpan sets the span of n given that the spans of its children have already
+// been set. The span covers n's own element, its children, the closing paren of
+// any paren level that n opens, and the element that caused n's error (if any).
+// A placeholder for a missing operand has an empty span at the end of the
+// preceding element (or at the start of the input).
+func setSpan[T any, E Element](n *node[T, E], elements []E) {
+	if n.missing != notMissing {
+		i := n.err.Index
+		if n.missing == missingBefore {
+			i--
+		}
+		if i == -1 {
+			start, _ := elemAs[Spanned](&elements[0]).Span()
+			n.start, n.end = start, start
+		} else {
+			_, end := elemAs[Spanned](&elements[i]).Span()
+			n.start, n.end = end, end
+		}
+		return
+	}
+
+	start, end := math.MaxInt, math.MinInt
+	extend := func(s, e int) {
+		start = min(start, s)
+		end = max(end, e)
+	}
+	if n.index != -1 {
+		extend(elemAs[Spanned](&elements[n.index]).Span())
+	}
+	if n.closeIndex != -1 {
+		extend(elemAs[Spanned](&elements[n.closeIndex]).Span())
+	}
+	if n.err != nil {
+		extend(elemAs[Spanned](&n.err.Elem).Span())
+	}
+	if n.left != nil {
+		extend(n.left.start, n.left.end)
+	}
+	if n.right != nil {
+		extend(n.right.start, n.right.end)
+	}
+	for slot := n.slots; slot != nil; slot = slot.slots {
+		if slot.left != nil {
+			extend(slot.left.start, slot.left.end)
+		}
+		if slot.closeIndex != -1 {
+			extend(elemAs[Spanned](&elements[slot.closeIndex]).Span())
+		}
+	}
+	n.start, n.end = start, end
+}
+
+// elemAs returns the element at e as an I. If E's methods have value
+// receivers, the result wraps e rather than a copy of the element, as boxing a
+// copy would allocate on every call for most non-pointer element types.
+func elemAs[I any, E any](e *E) I {
+	if i, ok := any(e).(I); ok {
+		return i
+	}
+	return any(*e).(I)
+}
+
 func leftTreeNodeOf[T any, E Element](n *node[T, E]) *T {
 	if n.left != nil {
 		return n.left.treeNode
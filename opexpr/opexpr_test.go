@@ -1,6 +1,7 @@
 package opexpr
 
 import (
+	"errors"
 	"iter"
 	"math/rand"
 	"strings"
@@ -378,3 +379,69 @@ func randomStringElement(r *rand.Rand) StringElement {
 func emptySeq[T any]() iter.Seq[T] {
 	return func(yield func(T) bool) {}
 }
+
+func TestParseErrorPositions(t *testing.T) {
+	cases := []struct {
+		input        string
+		kind         ParseErrorKind
+		index        int
+		relatedIndex int
+	}{
+		{"1 + 2 )", ParseErrorUnexpectedClosingParen, 3, -1},
+		{"1 + ( 2 ]", ParseErrorWrongKindOfClosingParen, 4, 2},
+		{"( 1 + ( 2 )", ParseErrorMissingClosingParen, 5, 0},
+		{"( 1 + [ 2", ParseErrorMissingClosingParen, 4, 3},
+		{"1 [[ 2", ParseErrorMissingClosingParen, 2, 1},
+		{"1 + * 2", ParseErrorUnexpectedOperator, 2, -1},
+		{"1 2", ParseErrorUnexpectedValue, 1, -1},
+		{"1 +", ParseErrorUnexpectedOperator, 1, -1},
+	}
+
+	pool := MakeNodePool[SimpleNode[StringElement], StringElement](32)
+	for _, c := range cases {
+		elems := MakeStringElements(c.input)
+		_, errs := ParseSlice(elems, pool)
+		if len(errs) != 1 {
+			t.Errorf("%v: expected 1 error, got %v", c.input, len(errs))
+			continue
+		}
+		pe := errs[0]
+		if pe.Kind != c.kind || pe.Index != c.index || pe.RelatedIndex != c.relatedIndex {
+			t.Errorf("%v: expected %v at %v related to %v, got %v at %v related to %v", c.input, c.kind, c.index, c.relatedIndex, pe.Kind, pe.Index, pe.RelatedIndex)
+			continue
+		}
+		if pe.Elem != elems[pe.Index] {
+			t.Errorf("%v: expected element %v, got %v", c.input, elems[pe.Index], pe.Elem)
+		}
+		if pe.RelatedIndex != -1 && pe.Related != elems[pe.RelatedIndex] {
+			t.Errorf("%v: expected related element %v, got %v", c.input, elems[pe.RelatedIndex], pe.Related)
+		}
+	}
+}
+
+func TestParseErrorAsError(t *testing.T) {
+	pool := MakeNodePool[SimpleNode[StringElement], StringElement](32)
+
+	_, errs := ParseSlice(MakeStringElements("1 + 2"), pool)
+	if JoinParseErrors(errs) != nil {
+		t.Errorf("Expected nil error for a successful parse")
+	}
+
+	_, errs = ParseSlice(MakeStringElements("( 1 + * 2 ]"), pool)
+	err := JoinParseErrors(errs)
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	expected := "ParseErrorUnexpectedOperator at element 3 (*)\nParseErrorWrongKindOfClosingParen at element 5 (]) for element 0 (()"
+	if err.Error() != expected {
+		t.Errorf("Expected error message:\n%v\nGot:\n%v", expected, err.Error())
+	}
+
+	var pe *ParseError[StringElement]
+	if !errors.As(err, &pe) || pe != errs[0] {
+		t.Errorf("Expected errors.As to find the first parse error")
+	}
+	if !errors.Is(err, errs[1]) {
+		t.Errorf("Expected errors.Is to find the second parse error")
+	}
+}
@@ -0,0 +1,203 @@
+package opexpr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// recoveringElement is a StringElement that implements MissingOperandBuilder.
+// Placeholders for missing operands are shown as '?'.
+type recoveringElement struct {
+	StringElement
+}
+
+func makeRecoveringElements(s string) []recoveringElement {
+	var elems []recoveringElement
+	for _, se := range MakeStringElements(s) {
+		elems = append(elems, recoveringElement{se})
+	}
+	return elems
+}
+
+func (e recoveringElement) MakeNode(arg1, arg2 *SimpleNode[recoveringElement]) *SimpleNode[recoveringElement] {
+	return &SimpleNode[recoveringElement]{arg1, arg2, e}
+}
+
+func (e recoveringElement) MakeErrorNode(pe *ParseError[recoveringElement], arg1, arg2 *SimpleNode[recoveringElement]) *SimpleNode[recoveringElement] {
+	se := StringElement(fmt.Sprintf("@error:%v", ShowParseError(pe)))
+	return &SimpleNode[recoveringElement]{arg1, arg2, recoveringElement{se}}
+}
+
+func (e recoveringElement) MakeMissingOperand(pe *ParseError[recoveringElement]) *SimpleNode[recoveringElement] {
+	return &SimpleNode[recoveringElement]{nil, nil, recoveringElement{"?"}}
+}
+
+// testRecovery checks that parsing input in recovery mode gives the same tree
+// as a correct parse of corrected (where missing operands are written as '?'),
+// and that the expected errors are reported.
+func testRecovery(t *testing.T, input, corrected string, errors ...string) {
+	t.Logf("Input: %v\n", input)
+
+	correctRoot, correctErrs := ParseSlice(MakeStringElements(corrected), MakeNodePool[SimpleNode[StringElement], StringElement](32))
+	if len(correctErrs) != 0 {
+		t.Fatalf("Unexpected errors for %v: %v\n", corrected, correctErrs)
+	}
+	output := ShowSimpleNode(correctRoot)
+
+	pool := MakeNodePool[SimpleNode[recoveringElement], recoveringElement](32)
+	root, errs := ParseSlice(makeRecoveringElements(input), pool)
+	if o := ShowSimpleNode(root); o != output {
+		t.Errorf("Expected output: %v\nGot: %v\n", output, o)
+	}
+	got := make([]string, 0)
+	for _, pe := range errs {
+		got = append(got, pe.Error())
+	}
+	if strings.Join(got, "; ") != strings.Join(errors, "; ") {
+		t.Errorf("Expected errors: %v\nGot: %v\n", errors, got)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	testRecovery(t, "", "")
+	testRecovery(t, "a + b * c", "a + b * c")
+	testRecovery(t, "( )", "( ? )", "ParseErrorMissingOperand at element 1 ())")
+	testRecovery(t, "f [[ ]", "f [[ ? ]", "ParseErrorMissingOperand at element 2 (])")
+	testRecovery(t, "a + * b", "a + ? * b", "ParseErrorMissingOperand at element 2 (*)")
+	testRecovery(t, "a +", "a + ?", "ParseErrorMissingOperand at element 1 (+)")
+	testRecovery(t, "* b", "? * b", "ParseErrorMissingOperand at element 0 (*)")
+	testRecovery(t, "+", "? + ?", "ParseErrorMissingOperand at element 0 (+)", "ParseErrorMissingOperand at element 0 (+)")
+	testRecovery(t, "a +{ b +{ * c", "a +{ b +{ ? * c", "ParseErrorMissingOperand at element 4 (*)")
+	testRecovery(t, "( a + ) * b", "( a + ? ) * b", "ParseErrorMissingOperand at element 3 ())")
+	testRecovery(t, "! #", "! ? #", "ParseErrorMissingOperand at element 1 (#)")
+	testRecovery(t, "f [[ a + ]$ - b", "f [[ a + ? ] - b", "ParseErrorMissingOperand at element 4 (]$)")
+}
+
+func TestRecoveryParens(t *testing.T) {
+	testRecovery(t, "( a + b", "( a + b )", "ParseErrorMissingClosingParen at element 3 (b) for element 0 (()")
+	testRecovery(t, "( [ a", "( [ a ] )", "ParseErrorMissingClosingParen at element 2 (a) for element 1 ([)", "ParseErrorMissingClosingParen at element 2 (a) for element 0 (()")
+	testRecovery(t, "a + b ) * c", "a + b * c", "ParseErrorUnexpectedClosingParen at element 3 ())")
+	testRecovery(t, "( a + b ] * c", "( a + b ) * c", "ParseErrorWrongKindOfClosingParen at element 4 (]) for element 0 (()")
+	testRecovery(t, "( a +", "( a + ? )", "ParseErrorMissingOperand at element 2 (+)", "ParseErrorMissingClosingParen at element 2 (+) for element 0 (()")
+	testRecovery(t, "a + (", "a + ( ? )", "ParseErrorMissingOperand at element 2 (()", "ParseErrorMissingClosingParen at element 2 (() for element 2 (()")
+}
+
+func TestRecoveryKeepsErrorNodesForMissingOperators(t *testing.T) {
+	pool := MakeNodePool[SimpleNode[recoveringElement], recoveringElement](32)
+	root, errs := ParseSlice(makeRecoveringElements("a b"), pool)
+	if o := ShowSimpleNode(root); o != "⎡a @error:ParseErrorUnexpectedValue@b b⎦" {
+		t.Errorf("Unexpected output: %v\n", o)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %v\n", len(errs))
+	}
+
+	jux := recoveringElement{"/"}
+	root, errs = ParseSliceWithJuxtaposition(makeRecoveringElements("a b +"), &jux, pool)
+	if o := ShowSimpleNode(root); o != "⎡⎡a / b⎦ + ?⎦" {
+		t.Errorf("Unexpected output: %v\n", o)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %v\n", len(errs))
+	}
+}
+
+// spannedRecoveringElement is a spannedElement that implements
+// SpannedMissingOperandBuilder.
+type spannedRecoveringElement struct {
+	spannedElement
+}
+
+func (e spannedRecoveringElement) MakeNode(arg1, arg2 *SimpleNode[spannedRecoveringElement]) *SimpleNode[spannedRecoveringElement] {
+	panic("Expecting MakeSpannedNode to be called instead of MakeNode")
+}
+
+func (e spannedRecoveringElement) MakeErrorNode(pe *ParseError[spannedRecoveringElement], arg1, arg2 *SimpleNode[spannedRecoveringElement]) *SimpleNode[spannedRecoveringElement] {
+	panic("Expecting MakeSpannedErrorNode to be called instead of MakeErrorNode")
+}
+
+func (e spannedRecoveringElement) MakeSpannedNode(arg1, arg2 *SimpleNode[spannedRecoveringElement], start, end int) *SimpleNode[spannedRecoveringElement] {
+	return &SimpleNode[spannedRecoveringElement]{arg1, arg2, spannedRecoveringElement{spannedElement{e.StringElement, start, end}}}
+}
+
+func (e spannedRecoveringElement) MakeSpannedErrorNode(pe *ParseError[spannedRecoveringElement], arg1, arg2 *SimpleNode[spannedRecoveringElement], start, end int) *SimpleNode[spannedRecoveringElement] {
+	se := StringElement(fmt.Sprintf("@error:%v", pe.Kind))
+	return &SimpleNode[spannedRecoveringElement]{arg1, arg2, spannedRecoveringElement{spannedElement{se, start, end}}}
+}
+
+func (e spannedRecoveringElement) MakeMissingOperand(pe *ParseError[spannedRecoveringElement]) *SimpleNode[spannedRecoveringElement] {
+	panic("Expecting MakeSpannedMissingOperand to be called instead of MakeMissingOperand")
+}
+
+func (e spannedRecoveringElement) MakeSpannedMissingOperand(pe *ParseError[spannedRecoveringElement], start, end int) *SimpleNode[spannedRecoveringElement] {
+	return &SimpleNode[spannedRecoveringElement]{nil, nil, spannedRecoveringElement{spannedElement{"?", start, end}}}
+}
+
+func testRecoverySpans(t *testing.T, input, output string) {
+	t.Logf("Input: %v\n", input)
+
+	var elems []spannedRecoveringElement
+	for _, e := range makeSpannedElements(input) {
+		elems = append(elems, spannedRecoveringElement{e})
+	}
+	pool := MakeNodePool[SimpleNode[spannedRecoveringElement], spannedRecoveringElement](32)
+	root, _ := ParseSlice(elems, pool)
+
+	// Placeholders have empty spans, so they are shown as '?' followed by
+	// their offset.
+	var spans []string
+	var helper func(n *SimpleNode[spannedRecoveringElement])
+	helper = func(n *SimpleNode[spannedRecoveringElement]) {
+		if n == nil {
+			return
+		}
+		if n.Value.StringElement == "?" {
+			if n.Value.start != n.Value.end {
+				t.Errorf("Expected empty span for placeholder, got %v-%v\n", n.Value.start, n.Value.end)
+			}
+			spans = append(spans, fmt.Sprintf("?@%v", n.Value.start))
+		} else {
+			spans = append(spans, input[n.Value.start:n.Value.end])
+		}
+		helper(n.Left)
+		helper(n.Right)
+	}
+	helper(root)
+	if s := strings.Join(spans, " | "); s != output {
+		t.Errorf("Expected spans: %v\nGot: %v\n", output, s)
+	}
+}
+
+func TestRecoverySpans(t *testing.T) {
+	testRecoverySpans(t, "a + * b", "a + * b | a + | a | ?@3 | b")
+	testRecoverySpans(t, "* b", "* b | ?@0 | b")
+	testRecoverySpans(t, "a +", "a + | a | ?@3")
+	testRecoverySpans(t, "( a + ) * b", "( a + ) * b | ( a + ) | a + | a | ?@5 | b")
+	testRecoverySpans(t, "( a + b", "( a + b | a + b | a | b")
+}
+
+func TestRecoveryFuzz(t *testing.T) {
+	// In recovery mode, error nodes are used only for juxtaposed values and
+	// prefix operators.
+	source := rand.NewSource(12345)
+	rand := rand.New(source)
+	pool := MakeNodePool[SimpleNode[recoveringElement], recoveringElement](64)
+	for i := 0; i < 10000; i++ {
+		var elems []recoveringElement
+		for _, se := range randomStringElementSequence(rand) {
+			elems = append(elems, recoveringElement{se})
+		}
+		root, errs := ParseSlice(elems, pool)
+		nErrorNodes := 0
+		for _, pe := range errs {
+			if pe.Kind == ParseErrorUnexpectedValue || pe.Kind == ParseErrorUnexpectedOperator {
+				nErrorNodes++
+			}
+		}
+		if n := strings.Count(ShowSimpleNode(root), "@error"); n != nErrorNodes {
+			t.Errorf("Expected %v error nodes, got %v for input %v\n", nErrorNodes, n, elems)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package opexpr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// spannedElement is a StringElement that records its byte offsets in the input
+// string. The nodes that it builds record the span of their subtree.
+type spannedElement struct {
+	StringElement
+	start, end int
+}
+
+func makeSpannedElements(s string) []spannedElement {
+	var elems []spannedElement
+	offset := 0
+	for _, se := range MakeStringElements(s) {
+		elems = append(elems, spannedElement{se, offset, offset + len(se)})
+		offset += len(se) + 1
+	}
+	return elems
+}
+
+func (e spannedElement) Span() (int, int) {
+	return e.start, e.end
+}
+
+func (e spannedElement) MakeNode(arg1, arg2 *SimpleNode[spannedElement]) *SimpleNode[spannedElement] {
+	panic("Expecting MakeSpannedNode to be called instead of MakeNode")
+}
+
+func (e spannedElement) MakeErrorNode(pe *ParseError[spannedElement], arg1, arg2 *SimpleNode[spannedElement]) *SimpleNode[spannedElement] {
+	panic("Expecting MakeSpannedErrorNode to be called instead of MakeErrorNode")
+}
+
+func (e spannedElement) MakeSpannedNode(arg1, arg2 *SimpleNode[spannedElement], start, end int) *SimpleNode[spannedElement] {
+	return &SimpleNode[spannedElement]{arg1, arg2, spannedElement{e.StringElement, start, end}}
+}
+
+func (e spannedElement) MakeSpannedErrorNode(pe *ParseError[spannedElement], arg1, arg2 *SimpleNode[spannedElement], start, end int) *SimpleNode[spannedElement] {
+	se := StringElement(fmt.Sprintf("@error:%v", pe.Kind))
+	return &SimpleNode[spannedElement]{arg1, arg2, spannedElement{se, start, end}}
+}
+
+// showSpans shows the source text covered by each node of the tree, in
+// preorder.
+func showSpans(input string, n *SimpleNode[spannedElement]) string {
+	var o strings.Builder
+	var helper func(n *SimpleNode[spannedElement])
+	helper = func(n *SimpleNode[spannedElement]) {
+		if n == nil {
+			return
+		}
+		if o.Len() > 0 {
+			o.WriteString(" | ")
+		}
+		o.WriteString(input[n.Value.start:n.Value.end])
+		helper(n.Left)
+		helper(n.Right)
+	}
+	helper(n)
+	return o.String()
+}
+
+func testSpans(t *testing.T, nErrors int, input, output string) {
+	t.Logf("Input: %v\n", input)
+
+	pool := MakeNodePool[SimpleNode[spannedElement], spannedElement](32)
+	jux := spannedElement{StringElement("/"), -1, -1}
+	root, errs := ParseSliceWithJuxtaposition(makeSpannedElements(input), &jux, pool)
+	if len(errs) != nErrors {
+		t.Errorf("Expected %v errors, got %v\n", nErrors, len(errs))
+	}
+	if spans := showSpans(input, root); spans != output {
+		t.Errorf("Expected spans: %v\nGot: %v\n", output, spans)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	testSpans(t, 0, "1", "1")
+	testSpans(t, 0, "1 + 2 * 3", "1 + 2 * 3 | 1 + 2 | 1 | 2 | 3")
+	testSpans(t, 0, "( 1 + 2 ) * 3", "( 1 + 2 ) * 3 | ( 1 + 2 ) | 1 + 2 | 1 | 2 | 3")
+	testSpans(t, 0, "! ( 1 )", "! ( 1 ) | ( 1 ) | 1")
+	testSpans(t, 0, "f [[ 1 + 2 ] + 3", "f [[ 1 + 2 ] + 3 | f [[ 1 + 2 ] | f | 1 + 2 | 1 | 2 | 3")
+	testSpans(t, 0, "1 2 3", "1 2 3 | 1 2 | 1 | 2 | 3")
+	testSpans(t, 0, "( ( 1 )$ + 2", "( ( 1 )$ + 2 | ( ( 1 )$ | ( 1 )$ | 1 | 2")
+	testSpans(t, 0, "1 #", "1 # | 1")
+}
+
+func TestSpansWithErrors(t *testing.T) {
+	testSpans(t, 1, "( 1 + 2", "( 1 + 2 | ( 1 + 2 | 1 + 2 | 1 | 2")
+	testSpans(t, 1, "1 + 2 )", "1 + 2 ) | 1 + 2 | 1 | 2")
+	testSpans(t, 1, "( 1 + 2 ]", "( 1 + 2 ] | 1 + 2 ] | 1 + 2 | 1 | 2")
+	testSpans(t, 1, "1 +", "1 + | 1 | +")
+}
+
+func TestSpansFuzz(t *testing.T) {
+	// Every subtree's span must contain the spans of its children.
+	var check func(n *SimpleNode[spannedElement]) bool
+	check = func(n *SimpleNode[spannedElement]) bool {
+		for _, c := range []*SimpleNode[spannedElement]{n.Left, n.Right} {
+			if c != nil && (c.Value.start < n.Value.start || c.Value.end > n.Value.end || !check(c)) {
+				return false
+			}
+		}
+		return n.Value.start <= n.Value.end
+	}
+
+	source := rand.NewSource(12345)
+	rand := rand.New(source)
+	pool := MakeNodePool[SimpleNode[spannedElement], spannedElement](64)
+	for i := 0; i < 10000; i++ {
+		var sb strings.Builder
+		for j, se := range randomStringElementSequence(rand) {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(string(se))
+		}
+		input := sb.String()
+		root, _ := ParseSliceWithJuxtaposition(makeSpannedElements(input), nil, pool)
+		if root != nil && !check(root) {
+			t.Errorf("Bad spans for input %v: %v", input, showSpans(input, root))
+		}
+	}
+}
+
+// unspannedElement is the same as spannedElement except that it doesn't
+// implement Spanned or SpannedTreeBuilder.
+type unspannedElement struct {
+	StringElement
+	start, end int
+}
+
+func (e unspannedElement) MakeNode(arg1, arg2 *SimpleNode[unspannedElement]) *SimpleNode[unspannedElement] {
+	return &SimpleNode[unspannedElement]{arg1, arg2, e}
+}
+
+func (e unspannedElement) MakeErrorNode(pe *ParseError[unspannedElement], arg1, arg2 *SimpleNode[unspannedElement]) *SimpleNode[unspannedElement] {
+	return &SimpleNode[unspannedElement]{arg1, arg2, e}
+}
+
+func TestSpansDoNotAllocate(t *testing.T) {
+	// Span tracking should add no allocations beyond those made without it.
+	const input = "a + ( b * c ) + f [[ e ]"
+	var elems []unspannedElement
+	for _, e := range makeSpannedElements(input) {
+		elems = append(elems, unspannedElement(e))
+	}
+	pool := MakeNodePool[SimpleNode[unspannedElement], unspannedElement](32)
+	want := testing.AllocsPerRun(100, func() {
+		ParseSlice(elems, pool)
+	})
+
+	spannedElems := makeSpannedElements(input)
+	spannedPool := MakeNodePool[SimpleNode[spannedElement], spannedElement](32)
+	got := testing.AllocsPerRun(100, func() {
+		ParseSlice(spannedElems, spannedPool)
+	})
+	if got != want {
+		t.Errorf("Expecting %v allocations, got %v", want, got)
+	}
+}
@@ -123,7 +123,7 @@ func (elem StringElement) MakeNode(arg1, arg2 *SimpleNode[StringElement]) *Simpl
 }
 
 func (elem StringElement) MakeErrorNode(e *ParseError[StringElement], arg1, arg2 *SimpleNode[StringElement]) *SimpleNode[StringElement] {
-	se := StringElement(fmt.Sprintf("@error:%v", e))
+	se := StringElement(fmt.Sprintf("@error:%v", ShowParseError(e)))
 	return &SimpleNode[StringElement]{arg1, arg2, se}
 }
 
@@ -0,0 +1,391 @@
+package opexpr
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/addrummond/deckwreck/keywordmap"
+)
+
+// OperatorKind is the kind of an operator registered in an OperatorTable.
+type OperatorKind int
+
+const (
+	InfixOperator          OperatorKind = iota // binary operator
+	PrefixOperator                             // unary prefix operator
+	PostfixOperator                            // unary postfix operator
+	OpenParenOperator                          // opening paren
+	CloseParenOperator                         // closing paren
+	CloseAllParensOperator                     // closing paren that closes all open parens
+	ParentheticalOperator                      // parenthetical operator such as C's [...] indexation operator
+)
+
+func (k OperatorKind) String() string {
+	switch k {
+	case InfixOperator:
+		return "InfixOperator"
+	case PrefixOperator:
+		return "PrefixOperator"
+	case PostfixOperator:
+		return "PostfixOperator"
+	case OpenParenOperator:
+		return "OpenParenOperator"
+	case CloseParenOperator:
+		return "CloseParenOperator"
+	case CloseAllParensOperator:
+		return "CloseAllParensOperator"
+	case ParentheticalOperator:
+		return "ParentheticalOperator"
+	default:
+		panic(fmt.Sprintf("Unrecognized OperatorKind %v", int(k)))
+	}
+}
+
+// Associativity is the associativity of an infix operator.
+type Associativity int
+
+const (
+	LeftAssoc Associativity = iota
+	RightAssoc
+)
+
+// Operator describes an operator registered in an OperatorTable. Precedence
+// has the same meaning as for Element.Precedence. Assoc applies only to infix
+// operators, and ParenKind only to parens and parenthetical operators. If a
+// token is registered both as an opening paren and as a parenthetical operator
+// (e.g. '['), both operators should have the same ParenKind.
+type Operator struct {
+	Kind       OperatorKind
+	Precedence int
+	Assoc      Associativity
+	ParenKind  int
+}
+
+func (op Operator) expressionKind() ExpressionKind {
+	switch op.Kind {
+	case InfixOperator:
+		if op.Assoc == RightAssoc {
+			return BinaryRightAssoc
+		}
+		return BinaryLeftAssoc
+	case PrefixOperator:
+		return Prefix
+	case PostfixOperator:
+		return Postfix
+	case OpenParenOperator:
+		return OpenParen
+	case CloseParenOperator:
+		return CloseParen
+	case CloseAllParensOperator:
+		return CloseAllParens
+	case ParentheticalOperator:
+		return Parenthetical
+	default:
+		panic(fmt.Sprintf("Unrecognized OperatorKind %v", int(op.Kind)))
+	}
+}
+
+// hasLeft returns true if the operator is used when there is an expression to
+// its left. (Closing parens are used in either case.)
+func (op Operator) hasLeft() bool {
+	return op.Kind == InfixOperator || op.Kind == PostfixOperator || op.Kind == ParentheticalOperator
+}
+
+// The operators registered for a token. A token may have one operator for use
+// when there is an expression to its left (e.g. binary '-') and another for
+// use when there isn't (e.g. unary '-').
+type operatorEntry struct {
+	withLeft, withoutLeft       Operator
+	hasWithLeft, hasWithoutLeft bool
+
+	// The mixfix operators started by the token (with and without an
+	// expression to its left), and all the mixfix operators that the token is
+	// a part of.
+	mixfixWithLeft, mixfixWithoutLeft *MixfixOperator
+	mixfixParts                       []mixfixPart
+}
+
+type mixfixPart struct {
+	op   *MixfixOperator
+	part int
+}
+
+func (entry *operatorEntry) get(hasExpressionToLeft bool) Operator {
+	if (hasExpressionToLeft && entry.hasWithLeft) || !entry.hasWithoutLeft {
+		return entry.withLeft
+	}
+	return entry.withoutLeft
+}
+
+// OperatorTable declares the operators of a language, so that ExpressionKind,
+// Precedence and ParenKind need not be implemented by hand. Operators are
+// registered by token text (using AddOperator) or by token ID (using
+// AddOperatorID). Tokens that have no registered operator are values. A
+// TokenAdapter uses the table to wrap the tokens of a lexer as elements that
+// can be parsed. It should be constructed only via MakeOperatorTable.
+type OperatorTable struct {
+	trie        keywordmap.Trie
+	nTexts      int
+	textEntries []int
+	idEntries   map[int]int
+	entries     []operatorEntry
+}
+
+// MakeOperatorTable returns an empty operator table.
+func MakeOperatorTable() *OperatorTable {
+	return &OperatorTable{trie: keywordmap.MakeEmptyTrie[uint16](), idEntries: make(map[int]int)}
+}
+
+// AddOperator registers an operator for tokens with the given text. It
+// replaces any operator previously registered for the same text that is used
+// in the same position (i.e. with or without an expression to its left). It
+// returns false if the text is empty or the table is full.
+func (table *OperatorTable) AddOperator(text string, op Operator) bool {
+	ei := table.textEntry(text)
+	if ei == -1 {
+		return false
+	}
+	table.entries[ei].set(op)
+	return true
+}
+
+// AddOperatorID registers an operator for tokens with the given ID. It
+// otherwise behaves like AddOperator, except that it cannot fail. Operators
+// registered by ID take priority over operators registered by text.
+func (table *OperatorTable) AddOperatorID(id int, op Operator) {
+	table.entries[table.idEntry(id)].set(op)
+}
+
+// AddMixfixOperator registers a mixfix operator whose parts are the tokens
+// with the texts in op.Parts. A token may start only one mixfix operator in
+// each position (with or without an expression to its left), but may be a
+// later part of any number of mixfix operators. It returns false if op has
+// fewer than two parts, or if any of its parts is empty, or if the table is
+// full (in which case some of the parts may have been registered).
+func (table *OperatorTable) AddMixfixOperator(op *MixfixOperator) bool {
+	if len(op.Parts) < 2 {
+		return false
+	}
+	for _, text := range op.Parts {
+		if len(text) == 0 {
+			return false
+		}
+	}
+	for i, text := range op.Parts {
+		ei := table.textEntry(text)
+		if ei == -1 {
+			return false
+		}
+		table.entries[ei].setMixfix(op, i)
+	}
+	return true
+}
+
+// AddMixfixOperatorIDs registers a mixfix operator whose parts are the tokens
+// with the given IDs (one for each element of op.Parts). It otherwise behaves
+// like AddMixfixOperator, except that it returns false only if op has fewer
+// than two parts or the number of IDs is wrong.
+func (table *OperatorTable) AddMixfixOperatorIDs(op *MixfixOperator, ids []int) bool {
+	if len(op.Parts) < 2 || len(ids) != len(op.Parts) {
+		return false
+	}
+	for i, id := range ids {
+		table.entries[table.idEntry(id)].setMixfix(op, i)
+	}
+	return true
+}
+
+// textEntry returns the index of the entry for text, adding one if necessary.
+// It returns -1 if the text is empty or the table is full.
+func (table *OperatorTable) textEntry(text string) int {
+	if ti := keywordmap.KeywordIndex(table.trie, text); ti != -1 {
+		return table.textEntries[ti]
+	}
+	if len(text) == 0 || !keywordmap.AddToTrie(&table.trie, text, table.nTexts) {
+		return -1
+	}
+	ei := table.newEntry()
+	table.textEntries = append(table.textEntries, ei)
+	table.nTexts++
+	return ei
+}
+
+// idEntry returns the index of the entry for id, adding one if necessary.
+func (table *OperatorTable) idEntry(id int) int {
+	ei, ok := table.idEntries[id]
+	if !ok {
+		ei = table.newEntry()
+		table.idEntries[id] = ei
+	}
+	return ei
+}
+
+func (table *OperatorTable) newEntry() int {
+	table.entries = append(table.entries, operatorEntry{})
+	return len(table.entries) - 1
+}
+
+func (entry *operatorEntry) set(op Operator) {
+	if op.hasLeft() || op.Kind == CloseParenOperator || op.Kind == CloseAllParensOperator {
+		entry.withLeft, entry.hasWithLeft = op, true
+	}
+	if !op.hasLeft() {
+		entry.withoutLeft, entry.hasWithoutLeft = op, true
+	}
+}
+
+func (entry *operatorEntry) setMixfix(op *MixfixOperator, part int) {
+	entry.mixfixParts = append(entry.mixfixParts, mixfixPart{op, part})
+	if part == 0 && op.LeftHole {
+		entry.mixfixWithLeft = op
+	} else if part == 0 {
+		entry.mixfixWithoutLeft = op
+	}
+}
+
+// lookup returns 1 + the index of the entry for a token, or 0 if the token is
+// a value.
+func (table *OperatorTable) lookup(text string, hasText bool, id int, hasID bool) int {
+	if hasID {
+		if ei, ok := table.idEntries[id]; ok {
+			return ei + 1
+		}
+	}
+	if hasText {
+		if ti := keywordmap.KeywordIndex(table.trie, text); ti != -1 {
+			return table.textEntries[ti] + 1
+		}
+	}
+	return 0
+}
+
+// TokenAdapter wraps tokens of type Tok as TokenElements, which implement
+// TreeBuilder using an OperatorTable. At least one of TokenText and TokenID
+// must be non-nil. MakeNode and MakeErrorNode are used to implement the
+// corresponding methods of TreeBuilder, and are given the token itself rather
+// than its TokenElement. MakeMixfixNode is used to implement the corresponding
+// method of MixfixBuilder, and need be set only if the table has mixfix
+// operators.
+type TokenAdapter[T, Tok any] struct {
+	Table *OperatorTable
+	// TokenText returns the text of a token for lookup of operators registered
+	// using AddOperator.
+	TokenText func(token Tok) string
+	// TokenID returns the ID of a token for lookup of operators registered
+	// using AddOperatorID.
+	TokenID        func(token Tok) int
+	MakeNode       func(token Tok, leftArg, rightArg *T) *T
+	MakeErrorNode  func(pe *ParseError[TokenElement[T, Tok]], leftChild, rightChild *T) *T
+	MakeMixfixNode func(parts []TokenElement[T, Tok], operands []*T) *T
+}
+
+// TokenElement is a token wrapped by a TokenAdapter. The operator table is
+// consulted once, when the token is wrapped. The zero value is a value.
+type TokenElement[T, Tok any] struct {
+	Token   Tok
+	adapter *TokenAdapter[T, Tok]
+	// 0 for a value, or 1 + the index of the token's entry in the table.
+	entry int
+}
+
+// Wrap wraps a single token.
+func (adapter *TokenAdapter[T, Tok]) Wrap(token Tok) TokenElement[T, Tok] {
+	var text string
+	var id int
+	if adapter.TokenText != nil {
+		text = adapter.TokenText(token)
+	}
+	if adapter.TokenID != nil {
+		id = adapter.TokenID(token)
+	}
+	return TokenElement[T, Tok]{token, adapter, adapter.Table.lookup(text, adapter.TokenText != nil, id, adapter.TokenID != nil)}
+}
+
+// WrapSlice wraps a slice of tokens. The result can be passed to ParseSlice.
+func (adapter *TokenAdapter[T, Tok]) WrapSlice(tokens []Tok) []TokenElement[T, Tok] {
+	elems := make([]TokenElement[T, Tok], len(tokens))
+	for i, tok := range tokens {
+		elems[i] = adapter.Wrap(tok)
+	}
+	return elems
+}
+
+// WrapSeq wraps a sequence of tokens. The result can be passed to ParseSeq.
+func (adapter *TokenAdapter[T, Tok]) WrapSeq(tokens iter.Seq[Tok]) iter.Seq[TokenElement[T, Tok]] {
+	return func(yield func(TokenElement[T, Tok]) bool) {
+		for tok := range tokens {
+			if !yield(adapter.Wrap(tok)) {
+				return
+			}
+		}
+	}
+}
+
+func (e TokenElement[T, Tok]) String() string {
+	return fmt.Sprint(e.Token)
+}
+
+func (e TokenElement[T, Tok]) ParenKind() int {
+	if e.entry == 0 {
+		return -1
+	}
+	entry := &e.adapter.Table.entries[e.entry-1]
+	if entry.hasWithoutLeft && entry.withoutLeft.Kind == OpenParenOperator {
+		return entry.withoutLeft.ParenKind
+	}
+	return entry.withLeft.ParenKind
+}
+
+func (e TokenElement[T, Tok]) ExpressionKind(hasExpressionToLeft bool) ExpressionKind {
+	if e.entry == 0 {
+		return Value
+	}
+	return e.adapter.Table.entries[e.entry-1].get(hasExpressionToLeft).expressionKind()
+}
+
+func (e TokenElement[T, Tok]) Precedence(hasExpressionToLeft bool) int {
+	if e.entry == 0 {
+		return 0
+	}
+	return e.adapter.Table.entries[e.entry-1].get(hasExpressionToLeft).Precedence
+}
+
+func (e TokenElement[T, Tok]) MakeNode(leftArg, rightArg *T) *T {
+	return e.adapter.MakeNode(e.Token, leftArg, rightArg)
+}
+
+func (e TokenElement[T, Tok]) MakeErrorNode(pe *ParseError[TokenElement[T, Tok]], leftChild, rightChild *T) *T {
+	// The receiver may be the zero value for an error node that has no
+	// corresponding element.
+	return pe.Elem.adapter.MakeErrorNode(pe, leftChild, rightChild)
+}
+
+func (e TokenElement[T, Tok]) StartsMixfix(hasExpressionToLeft bool) *MixfixOperator {
+	if e.entry == 0 {
+		return nil
+	}
+	entry := &e.adapter.Table.entries[e.entry-1]
+	if (hasExpressionToLeft && entry.mixfixWithLeft != nil) || entry.mixfixWithoutLeft == nil {
+		return entry.mixfixWithLeft
+	}
+	return entry.mixfixWithoutLeft
+}
+
+func (e TokenElement[T, Tok]) MixfixPart(op *MixfixOperator) int {
+	if e.entry == 0 {
+		return -1
+	}
+	for _, mp := range e.adapter.Table.entries[e.entry-1].mixfixParts {
+		if mp.op == op {
+			return mp.part
+		}
+	}
+	return -1
+}
+
+func (e TokenElement[T, Tok]) MakeMixfixNode(parts []TokenElement[T, Tok], operands []*T) *T {
+	if e.adapter.MakeMixfixNode == nil {
+		panic("TokenAdapter.MakeMixfixNode must be set if the table has mixfix operators")
+	}
+	return e.adapter.MakeMixfixNode(parts, operands)
+}
@@ -0,0 +1,154 @@
+package opexpr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testToken struct {
+	text string
+	id   int
+}
+
+func (tok testToken) String() string {
+	return tok.text
+}
+
+func makeTestTable() *OperatorTable {
+	table := MakeOperatorTable()
+	table.AddOperator("+", Operator{Kind: InfixOperator, Precedence: 2})
+	table.AddOperator("-", Operator{Kind: InfixOperator, Precedence: 2})
+	table.AddOperator("-", Operator{Kind: PrefixOperator, Precedence: 0})
+	table.AddOperator("*", Operator{Kind: InfixOperator, Precedence: 1})
+	table.AddOperator("^", Operator{Kind: InfixOperator, Precedence: 0, Assoc: RightAssoc})
+	table.AddOperator("!", Operator{Kind: PostfixOperator, Precedence: 0})
+	table.AddOperator("(", Operator{Kind: OpenParenOperator})
+	table.AddOperator(")", Operator{Kind: CloseParenOperator})
+	table.AddOperator("[", Operator{Kind: OpenParenOperator, ParenKind: 1})
+	table.AddOperator("[", Operator{Kind: ParentheticalOperator, Precedence: 0, ParenKind: 1})
+	table.AddOperator("]", Operator{Kind: CloseParenOperator, ParenKind: 1})
+	table.AddOperator(")$", Operator{Kind: CloseAllParensOperator})
+	return table
+}
+
+func makeTestAdapter(table *OperatorTable) *TokenAdapter[string, testToken] {
+	return &TokenAdapter[string, testToken]{
+		Table:     table,
+		TokenText: func(tok testToken) string { return tok.text },
+		TokenID:   func(tok testToken) int { return tok.id },
+		MakeNode: func(tok testToken, l, r *string) *string {
+			var s string
+			switch {
+			case l != nil && r != nil:
+				s = fmt.Sprintf("⎡%v %v %v⎦", *l, tok.text, *r)
+			case l != nil:
+				s = fmt.Sprintf("⎡%v%v⎦", *l, tok.text)
+			case r != nil && (tok.text == "(" || tok.text == "["):
+				s = fmt.Sprintf("%v%v", tok.text, *r)
+			case r != nil:
+				s = fmt.Sprintf("⎡%v%v⎦", tok.text, *r)
+			default:
+				s = tok.text
+			}
+			return &s
+		},
+		MakeErrorNode: func(pe *ParseError[TokenElement[string, testToken]], l, r *string) *string {
+			s := fmt.Sprintf("@error:%v", pe.Kind)
+			return &s
+		},
+	}
+}
+
+func makeTestTokens(s string) []testToken {
+	var toks []testToken
+	for _, t := range strings.Split(s, " ") {
+		toks = append(toks, testToken{t, 0})
+	}
+	return toks
+}
+
+// showStringNode shows a tree built by the test adapter, which may be nil.
+func showStringNode(n *string) string {
+	if n == nil {
+		return "<nil>"
+	}
+	return *n
+}
+
+func testTable(t *testing.T, adapter *TokenAdapter[string, testToken], nErrors int, input, output string) {
+	t.Logf("Input: %v\n", input)
+
+	pool := MakeNodePool[string, TokenElement[string, testToken]](32)
+	root, errs := ParseSlice(adapter.WrapSlice(makeTestTokens(input)), pool)
+	if len(errs) != nErrors {
+		t.Errorf("Expected %v errors, got %v\n", nErrors, len(errs))
+	}
+	if root == nil || *root != output {
+		t.Errorf("Expected output: %v\nGot: %v\n", output, showStringNode(root))
+	}
+}
+
+func TestOperatorTable(t *testing.T) {
+	adapter := makeTestAdapter(makeTestTable())
+	testTable(t, adapter, 0, "a", "a")
+	testTable(t, adapter, 0, "a + b * c", "⎡a + ⎡b * c⎦⎦")
+	testTable(t, adapter, 0, "a - b - c", "⎡⎡a - b⎦ - c⎦")
+	testTable(t, adapter, 0, "a ^ b ^ c", "⎡a ^ ⎡b ^ c⎦⎦")
+	// Prefix '-' binds tighter than '*', though infix '-' doesn't.
+	testTable(t, adapter, 0, "- a * - b", "⎡⎡-a⎦ * ⎡-b⎦⎦")
+	testTable(t, adapter, 0, "a ! + b", "⎡⎡a!⎦ + b⎦")
+	testTable(t, adapter, 0, "( a + b ) * c", "⎡(⎡a + b⎦ * c⎦")
+	testTable(t, adapter, 0, "f [ a ] + [ b ]", "⎡⎡f [ a⎦ + [b⎦")
+	testTable(t, adapter, 0, "( ( a )$ * b", "⎡((a * b⎦")
+	testTable(t, adapter, 1, "( a ]", "(@error:ParseErrorWrongKindOfClosingParen")
+	testTable(t, adapter, 1, "a +", "⎡a + @error:ParseErrorUnexpectedOperator⎦")
+}
+
+func TestOperatorTableIDs(t *testing.T) {
+	const plus, times = 1, 2
+	table := makeTestTable()
+	// 'plus' and 'times' swap the precedences of '+' and '*'.
+	table.AddOperatorID(plus, Operator{Kind: InfixOperator, Precedence: 1})
+	table.AddOperatorID(times, Operator{Kind: InfixOperator, Precedence: 2})
+	adapter := makeTestAdapter(table)
+
+	pool := MakeNodePool[string, TokenElement[string, testToken]](32)
+	toks := []testToken{{"a", 0}, {"*", times}, {"b", 0}, {"+", plus}, {"c", 0}}
+	root, errs := ParseSlice(adapter.WrapSlice(toks), pool)
+	if len(errs) != 0 || root == nil || *root != "⎡a * ⎡b + c⎦⎦" {
+		t.Errorf("Unexpected output %v (%v errors)\n", showStringNode(root), len(errs))
+	}
+
+	// Tokens whose IDs aren't registered are looked up by text.
+	toks = []testToken{{"a", 0}, {"*", 99}, {"b", 0}, {"+", 99}, {"c", 0}}
+	root, errs = ParseSeq(adapter.WrapSeq(func(yield func(testToken) bool) {
+		for _, tok := range toks {
+			if !yield(tok) {
+				return
+			}
+		}
+	}), pool)
+	if len(errs) != 0 || root == nil || *root != "⎡⎡a * b⎦ + c⎦" {
+		t.Errorf("Unexpected output %v (%v errors)\n", showStringNode(root), len(errs))
+	}
+}
+
+func TestOperatorTableReplace(t *testing.T) {
+	table := makeTestTable()
+	if table.AddOperator("", Operator{Kind: InfixOperator}) {
+		t.Errorf("Expected empty operator text to be rejected\n")
+	}
+	// Replaces only the infix '-', not the prefix '-'.
+	table.AddOperator("-", Operator{Kind: InfixOperator, Precedence: 3, Assoc: RightAssoc})
+	testTable(t, makeTestAdapter(table), 0, "- a - b - c", "⎡⎡-a⎦ - ⎡b - c⎦⎦")
+}
+
+func TestOperatorTableErrorStrings(t *testing.T) {
+	adapter := makeTestAdapter(makeTestTable())
+	pool := MakeNodePool[string, TokenElement[string, testToken]](32)
+	_, errs := ParseSlice(adapter.WrapSlice(makeTestTokens("( a ]")), pool)
+	if len(errs) != 1 || errs[0].Error() != "ParseErrorWrongKindOfClosingParen at element 2 (]) for element 0 (()" {
+		t.Errorf("Unexpected errors %v\n", errs)
+	}
+}